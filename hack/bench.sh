@@ -0,0 +1,66 @@
+#!/usr/bin/env bash
+
+# Compares the crypto benchmarks of the current checkout against a base ref
+# and fails if any benchmark got more than THRESHOLD percent slower.
+#
+# Usage: hack/bench.sh <base-ref>
+
+set -euo pipefail
+
+BASE_REF=${1:-origin/master}
+THRESHOLD=${THRESHOLD:-20}
+BENCH_PKG=./internal/crypto/
+BENCH_FLAGS="-run=^$ -bench=. -benchmem -tags bench -count=6"
+
+WORKDIR=$(mktemp -d)
+trap 'git worktree remove --force "$WORKDIR/base" >/dev/null 2>&1 || true; rm -rf "$WORKDIR"' EXIT
+
+go test $BENCH_FLAGS $BENCH_PKG | tee "$WORKDIR/new.txt"
+
+git worktree add --detach "$WORKDIR/base" "$BASE_REF" >/dev/null
+
+if [ ! -f "$WORKDIR/base/internal/crypto/bench_test.go" ]
+then
+    echo "No benchmarks on $BASE_REF, nothing to compare against."
+    exit 0
+fi
+
+(cd "$WORKDIR/base" && go test $BENCH_FLAGS $BENCH_PKG) > "$WORKDIR/old.txt"
+
+# Averages ns/op per benchmark over all runs and compares old with new.
+awk -v threshold="$THRESHOLD" '
+    FNR == 1 { file++ }
+    /^Benchmark/ {
+        for (i = 3; i <= NF; i++) {
+            if ($i == "ns/op") {
+                name = $1
+                sum[file, name] += $(i - 1)
+                runs[file, name]++
+                names[name] = 1
+            }
+        }
+    }
+    END {
+        failed = 0
+        for (name in names) {
+            if (runs[1, name] == 0 || runs[2, name] == 0) {
+                continue
+            }
+
+            old = sum[1, name] / runs[1, name]
+            new = sum[2, name] / runs[2, name]
+            delta = (new - old) / old * 100
+
+            printf "%-50s %14.0f ns/op %14.0f ns/op %+7.1f%%\n", name, old, new, delta
+
+            if (delta > threshold) {
+                failed = 1
+            }
+        }
+
+        if (failed) {
+            printf "Some benchmarks regressed by more than %d%%.\n", threshold
+            exit 1
+        }
+    }
+' "$WORKDIR/old.txt" "$WORKDIR/new.txt"
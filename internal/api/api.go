@@ -15,6 +15,7 @@ import (
 	"github.com/supabase/auth/internal/observability"
 	"github.com/supabase/auth/internal/storage"
 	"github.com/supabase/auth/internal/utilities"
+	"github.com/supabase/auth/internal/utilities/siws"
 	"github.com/supabase/hibp"
 )
 
@@ -38,6 +39,10 @@ type API struct {
 	overrideTime func() time.Time
 
 	limiterOpts *LimiterOptions
+
+	// siwsNonceStore rejects replayed Solana messages in the web3 grant
+	// when set.
+	siwsNonceStore siws.NonceStore
 }
 
 func (a *API) Version() string {
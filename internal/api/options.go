@@ -7,6 +7,7 @@ import (
 	"github.com/didip/tollbooth/v5/limiter"
 	"github.com/supabase/auth/internal/conf"
 	"github.com/supabase/auth/internal/ratelimit"
+	"github.com/supabase/auth/internal/utilities/siws"
 )
 
 type Option interface {
@@ -34,6 +35,20 @@ type LimiterOptions struct {
 
 func (lo *LimiterOptions) apply(a *API) { a.limiterOpts = lo }
 
+type siwsNonceStoreOption struct {
+	store siws.NonceStore
+}
+
+func (o siwsNonceStoreOption) apply(a *API) { a.siwsNonceStore = o.store }
+
+// WithSIWSNonceStore makes the web3 grant record the nonce of each Solana
+// message in store and reject messages whose nonce was already used. The
+// store must be shared by all instances. Without it, a signed message can
+// be replayed until it expires.
+func WithSIWSNonceStore(store siws.NonceStore) Option {
+	return siwsNonceStoreOption{store: store}
+}
+
 func NewLimiterOptions(gc *conf.GlobalConfiguration) *LimiterOptions {
 	o := &LimiterOptions{}
 
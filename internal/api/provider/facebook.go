@@ -2,12 +2,10 @@ package provider
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"strings"
 
 	"github.com/supabase/auth/internal/conf"
+	"github.com/supabase/auth/internal/crypto"
 	"golang.org/x/oauth2"
 )
 
@@ -75,9 +73,7 @@ func (p facebookProvider) GetOAuthToken(code string) (*oauth2.Token, error) {
 }
 
 func (p facebookProvider) GetUserData(ctx context.Context, tok *oauth2.Token) (*UserProvidedData, error) {
-	hash := hmac.New(sha256.New, []byte(p.Config.ClientSecret))
-	hash.Write([]byte(tok.AccessToken))
-	appsecretProof := hex.EncodeToString(hash.Sum(nil))
+	appsecretProof := crypto.SignHMACHex([]byte(p.Config.ClientSecret), []byte(tok.AccessToken))
 
 	var u facebookUser
 	url := p.ProfileURL + "&appsecret_proof=" + appsecretProof
@@ -10,6 +10,7 @@ import (
 	"github.com/supabase/auth/internal/api/apierrors"
 	"github.com/supabase/auth/internal/api/provider"
 	"github.com/supabase/auth/internal/conf"
+	"github.com/supabase/auth/internal/crypto"
 	"github.com/supabase/auth/internal/models"
 	"github.com/supabase/auth/internal/observability"
 	"github.com/supabase/auth/internal/storage"
@@ -203,7 +204,7 @@ func (a *API) IdTokenGrant(ctx context.Context, w http.ResponseWriter, r *http.R
 		} else if tokenHasNonce && paramsHasNonce {
 			// verify nonce to mitigate replay attacks
 			hash := fmt.Sprintf("%x", sha256.Sum256([]byte(params.Nonce)))
-			if hash != idToken.Nonce {
+			if !crypto.SecureCompareString(hash, idToken.Nonce) {
 				return oauthError("invalid nonce", "Nonces mismatch")
 			}
 		}
@@ -618,7 +618,7 @@ func (a *API) verifyTokenHash(conn *storage.Connection, params *VerifyParams) (*
 	case mail.EmailOTPVerification:
 		sentAt := user.ConfirmationSentAt
 		params.Type = "signup"
-		if user.RecoveryToken == params.TokenHash {
+		if crypto.SecureCompareString(user.RecoveryToken, params.TokenHash) {
 			sentAt = user.RecoverySentAt
 			params.Type = "magiclink"
 		}
@@ -694,7 +694,7 @@ func (a *API) verifyUserAndToken(conn *storage.Connection, params *VerifyParams,
 			isOtpValid(tokenHash, user.EmailChangeTokenNew, user.EmailChangeSentAt, config.Mailer.OtpExp)
 	case phoneChangeVerification, smsVerification:
 		if testOTP, ok := config.Sms.GetTestOTP(params.Phone, time.Now()); ok {
-			if params.Token == testOTP {
+			if crypto.SecureCompareString(params.Token, testOTP) {
 				return user, nil
 			}
 		}
@@ -728,7 +728,7 @@ func isOtpValid(actual, expected string, sentAt *time.Time, otpExp uint) bool {
 	if expected == "" || sentAt == nil {
 		return false
 	}
-	return !isOtpExpired(sentAt, otpExp) && ((actual == expected) || ("pkce_"+actual == expected))
+	return !isOtpExpired(sentAt, otpExp) && (crypto.SecureCompareString(actual, expected) || crypto.SecureCompareString("pkce_"+actual, expected))
 }
 
 func isOtpExpired(sentAt *time.Time, otpExp uint) bool {
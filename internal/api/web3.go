@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -84,6 +85,16 @@ func (a *API) web3GrantSolana(ctx context.Context, w http.ResponseWriter, r *htt
 		return oauthError("invalid_grant", "Signed Solana message is using a Domain that does not match the one in URI which is not allowed on this server")
 	}
 
+	if parsedMessage.ChainID != "" && !siws.IsAllowedSolanaNetwork(parsedMessage.ChainID, config.External.Web3Solana.Networks) {
+		return oauthError("invalid_grant", "Signed Solana message is using a Chain ID that is not allowed on this server")
+	}
+
+	if config.External.Web3Solana.ValidateOrigin {
+		if origin := r.Header.Get("Origin"); origin != "" && !siws.ValidateOrigin(origin, parsedMessage.Domain) {
+			return oauthError("invalid_grant", "Signed Solana message is using a Domain that does not match the Origin of the request")
+		}
+	}
+
 	now := a.Now()
 
 	if !parsedMessage.NotBefore.IsZero() && now.Before(parsedMessage.NotBefore) {
@@ -106,6 +117,17 @@ func (a *API) web3GrantSolana(ctx context.Context, w http.ResponseWriter, r *htt
 		return oauthError("invalid_grant", "Solana message was issued too far in the future")
 	}
 
+	if err := parsedMessage.CheckReplay(a.siwsNonceStore, config.External.Web3Solana.MaximumValidityDuration); err != nil {
+		switch {
+		case errors.Is(err, siws.ErrNonceRequired):
+			return oauthError("invalid_grant", "Signed Solana message must include a Nonce")
+		case errors.Is(err, siws.ErrNonceSeen):
+			return oauthError("invalid_grant", "Signed Solana message has already been used")
+		default:
+			return internalServerError("Error checking Solana message nonce").WithInternalError(err)
+		}
+	}
+
 	providerId := strings.Join([]string{
 		"web3",
 		params.Chain,
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/supabase/auth/internal/api/apierrors"
 	"github.com/supabase/auth/internal/conf"
+	"github.com/supabase/auth/internal/utilities/siws"
 )
 
 type Web3TestSuite struct {
@@ -337,6 +338,197 @@ func (ts *Web3TestSuite) TestValidationRules_MismatchedDomainAndURIHostname() {
 	assert.Equal(ts.T(), "Signed Solana message is using a Domain that does not match the one in URI which is not allowed on this server", firstResult.ErrorDescription)
 }
 
+func (ts *Web3TestSuite) TestValidationRules_MismatchedDomainAndOrigin() {
+	defer func() {
+		ts.API.overrideTime = nil
+		ts.Config.External.Web3Solana.ValidateOrigin = false
+	}()
+
+	ts.API.overrideTime = func() time.Time {
+		t, _ := time.Parse(time.RFC3339, "2025-03-29T00:09:59Z")
+		return t
+	}
+
+	ts.Config.External.Web3Solana.ValidateOrigin = true
+
+	var buffer bytes.Buffer
+	require.NoError(ts.T(), json.NewEncoder(&buffer).Encode(map[string]interface{}{
+		"chain":     "solana",
+		"message":   "supabase.com wants you to sign in with your Solana account:\n2EZEiBdw47VHT6SpZSW9VnuSvBe7DxuYHBTxj19gxvv8\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z",
+		"signature": "aiKn+PAoB1OoXxS8H34HrB456YD4sKAVjeTjsxgkaQy3bkdV51WBTmUUE9lBU9kuXr0hTLI+1aTn5TFRbIF8CA==",
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/token?grant_type=web3", &buffer)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://supabase.green")
+
+	w := httptest.NewRecorder()
+	ts.API.handler.ServeHTTP(w, req)
+
+	assert.Equal(ts.T(), http.StatusBadRequest, w.Code)
+
+	var firstResult struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	assert.NoError(ts.T(), json.NewDecoder(w.Result().Body).Decode(&firstResult))
+
+	assert.Equal(ts.T(), "invalid_grant", firstResult.Error)
+	assert.Equal(ts.T(), "Signed Solana message is using a Domain that does not match the Origin of the request", firstResult.ErrorDescription)
+}
+
+func (ts *Web3TestSuite) TestValidationRules_OriginNotValidatedByDefault() {
+	defer func() {
+		ts.API.overrideTime = nil
+	}()
+
+	ts.API.overrideTime = func() time.Time {
+		t, _ := time.Parse(time.RFC3339, "2025-03-29T00:09:59Z")
+		return t
+	}
+
+	// native and extension clients don't send a web origin
+	for _, origin := range []string{"null", "capacitor://localhost", "chrome-extension://abcdef", "https://supabase.green"} {
+		var buffer bytes.Buffer
+		require.NoError(ts.T(), json.NewEncoder(&buffer).Encode(map[string]interface{}{
+			"chain":     "solana",
+			"message":   "supabase.com wants you to sign in with your Solana account:\n2EZEiBdw47VHT6SpZSW9VnuSvBe7DxuYHBTxj19gxvv8\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z",
+			"signature": "aiKn+PAoB1OoXxS8H34HrB456YD4sKAVjeTjsxgkaQy3bkdV51WBTmUUE9lBU9kuXr0hTLI+1aTn5TFRbIF8CA==",
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/token?grant_type=web3", &buffer)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+
+		w := httptest.NewRecorder()
+		ts.API.handler.ServeHTTP(w, req)
+
+		assert.Equal(ts.T(), http.StatusOK, w.Code, origin)
+	}
+}
+
+func (ts *Web3TestSuite) TestValidationRules_ChainIDNotAllowed() {
+	defer func() {
+		ts.API.overrideTime = nil
+		ts.Config.External.Web3Solana.Networks = nil
+	}()
+
+	ts.API.overrideTime = func() time.Time {
+		t, _ := time.Parse(time.RFC3339, "2025-03-29T00:09:59Z")
+		return t
+	}
+
+	body := map[string]interface{}{
+		"chain":     "solana",
+		"message":   "supabase.com wants you to sign in with your Solana account:\nEUzYVniKtgNNgFweMtRA9vciTWtE8MDTRfh6ai6VvXoU\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z\nChain ID: solana:my-cluster",
+		"signature": "s2zabsVWkjTneIImkkBpkTZCZiYsnkCFv0vc7iKzGHWKvvXrsKw5RrSWK0P+0dYFWwxid3973Y1tndLgW2VDCQ==",
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(ts.T(), json.NewEncoder(&buffer).Encode(body))
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/token?grant_type=web3", &buffer)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ts.API.handler.ServeHTTP(w, req)
+
+	assert.Equal(ts.T(), http.StatusBadRequest, w.Code)
+
+	var firstResult struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	assert.NoError(ts.T(), json.NewDecoder(w.Result().Body).Decode(&firstResult))
+
+	assert.Equal(ts.T(), "invalid_grant", firstResult.Error)
+	assert.Equal(ts.T(), "Signed Solana message is using a Chain ID that is not allowed on this server", firstResult.ErrorDescription)
+
+	// accepted once the network is configured
+	ts.Config.External.Web3Solana.Networks = []string{"solana:my-cluster"}
+
+	buffer.Reset()
+	require.NoError(ts.T(), json.NewEncoder(&buffer).Encode(body))
+
+	req = httptest.NewRequest(http.MethodPost, "http://localhost/token?grant_type=web3", &buffer)
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	ts.API.handler.ServeHTTP(w, req)
+
+	assert.Equal(ts.T(), http.StatusOK, w.Code)
+}
+
+func (ts *Web3TestSuite) TestValidationRules_Replayed() {
+	defer func() {
+		ts.API.overrideTime = nil
+		ts.API.siwsNonceStore = nil
+	}()
+
+	ts.API.overrideTime = func() time.Time {
+		t, _ := time.Parse(time.RFC3339, "2025-03-29T00:09:59Z")
+		return t
+	}
+
+	ts.API.siwsNonceStore = siws.NewMemoryNonceStore()
+
+	examples := []struct {
+		message   string
+		signature string
+		code      int
+		error     string
+	}{
+		{
+			message:   "supabase.com wants you to sign in with your Solana account:\nEUzYVniKtgNNgFweMtRA9vciTWtE8MDTRfh6ai6VvXoU\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nNonce: 7f3a9c2e1b\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z",
+			signature: "BuS53UU1vlKcgEyHH/bG8l1eBEVwSeOfU9+Wx7VzuSQXJ1rwxvxbOJoJyidNfk1zoI+Dc9Kxjfvqa5FrlU+aDQ==",
+			code:      http.StatusOK,
+		},
+		{
+			message:   "supabase.com wants you to sign in with your Solana account:\nEUzYVniKtgNNgFweMtRA9vciTWtE8MDTRfh6ai6VvXoU\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nNonce: 7f3a9c2e1b\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z",
+			signature: "BuS53UU1vlKcgEyHH/bG8l1eBEVwSeOfU9+Wx7VzuSQXJ1rwxvxbOJoJyidNfk1zoI+Dc9Kxjfvqa5FrlU+aDQ==",
+			code:      http.StatusBadRequest,
+			error:     "Signed Solana message has already been used",
+		},
+		{
+			message:   "supabase.com wants you to sign in with your Solana account:\n2EZEiBdw47VHT6SpZSW9VnuSvBe7DxuYHBTxj19gxvv8\n\nStatement\n\nURI: https://supabase.com/\nVersion: 1\nIssued At: 2025-03-29T00:00:00Z\nExpiration Time: 2025-03-29T00:10:00Z\nNot Before: 2025-03-29T00:00:00Z",
+			signature: "aiKn+PAoB1OoXxS8H34HrB456YD4sKAVjeTjsxgkaQy3bkdV51WBTmUUE9lBU9kuXr0hTLI+1aTn5TFRbIF8CA==",
+			code:      http.StatusBadRequest,
+			error:     "Signed Solana message must include a Nonce",
+		},
+	}
+
+	for _, example := range examples {
+		var buffer bytes.Buffer
+		require.NoError(ts.T(), json.NewEncoder(&buffer).Encode(map[string]interface{}{
+			"chain":     "solana",
+			"message":   example.message,
+			"signature": example.signature,
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "http://localhost/token?grant_type=web3", &buffer)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		ts.API.handler.ServeHTTP(w, req)
+
+		assert.Equal(ts.T(), example.code, w.Code)
+
+		if example.error != "" {
+			var result struct {
+				Error            string `json:"error"`
+				ErrorDescription string `json:"error_description"`
+			}
+
+			assert.NoError(ts.T(), json.NewDecoder(w.Result().Body).Decode(&result))
+
+			assert.Equal(ts.T(), "invalid_grant", result.Error)
+			assert.Equal(ts.T(), example.error, result.ErrorDescription)
+		}
+	}
+}
+
 func (ts *Web3TestSuite) TestValidationRules_ValidatedBeforeNotBefore() {
 	defer func() {
 		ts.API.overrideTime = nil
@@ -19,6 +19,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/supabase/auth/internal/utilities/siws"
 	"gopkg.in/gomail.v2"
 )
 
@@ -346,6 +347,26 @@ type ProviderConfiguration struct {
 type SolanaConfiguration struct {
 	Enabled                 bool          `json:"enabled,omitempty" split_words:"true"`
 	MaximumValidityDuration time.Duration `json:"maximum_validity_duration,omitempty" default:"10m" split_words:"true"`
+
+	// Networks are additional chain IDs accepted in messages, such as
+	// "solana:my-cluster" for a local validator cluster.
+	Networks []string `json:"networks,omitempty"`
+
+	// ValidateOrigin rejects messages whose domain does not match the
+	// Origin header of the request. Native, webview and extension clients
+	// send origins such as "null" or "capacitor://localhost", so this is
+	// only suitable when all clients are web apps.
+	ValidateOrigin bool `json:"validate_origin,omitempty" split_words:"true"`
+}
+
+func (c *SolanaConfiguration) Validate() error {
+	for _, network := range c.Networks {
+		if !siws.IsValidSolanaChainID(network) {
+			return fmt.Errorf("conf: Solana network %q is not a valid chain ID, must be in the form solana:<network>", network)
+		}
+	}
+
+	return nil
 }
 
 type SMTPConfiguration struct {
@@ -1105,6 +1126,7 @@ func (c *GlobalConfiguration) Validate() error {
 		&c.Sessions,
 		&c.Hook,
 		&c.JWT.Keys,
+		&c.External.Web3Solana,
 	}
 
 	for _, validatable := range validatables {
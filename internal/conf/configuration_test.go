@@ -494,6 +494,18 @@ func TestValidate(t *testing.T) {
 			val: &SessionsConfiguration{Timebox: toPtr(time.Duration(1))},
 		},
 
+		{
+			val: &SolanaConfiguration{},
+		},
+		{
+			val: &SolanaConfiguration{Networks: []string{"solana:my-cluster"}},
+		},
+		{
+			val: &SolanaConfiguration{Networks: []string{"my-cluster"}},
+			err: `conf: Solana network "my-cluster" is not a valid chain ID,` +
+				` must be in the form solana:<network>`,
+		},
+
 		{
 			val: &SMTPConfiguration{},
 		},
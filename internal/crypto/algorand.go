@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrSignatureMismatch is returned when a signature is well formed but was
+// not produced by the key behind the address.
+var ErrSignatureMismatch = errors.New("crypto: signature does not match address")
+
+// algorandBytesPrefix is prepended by the Algorand SDKs when signing
+// arbitrary bytes, so that they can't be confused with transactions.
+const algorandBytesPrefix = "MX"
+
+var algorandAddressEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// VerifyAlgorandSignature checks a base64 encoded Ed25519 signature over the
+// message, as produced by the Algorand SDKs' signBytes, against an Algorand
+// address. The address is the base32 encoding of the public key followed by
+// the last 4 bytes of its SHA-512/256 hash as a checksum.
+func VerifyAlgorandSignature(message, signatureBase64, algoAddress string) error {
+	decoded, err := algorandAddressEncoding.DecodeString(algoAddress)
+	if err != nil || len(decoded) != ed25519.PublicKeySize+4 {
+		return errors.New("crypto: Algorand address is not valid")
+	}
+
+	publicKey := decoded[:ed25519.PublicKeySize]
+	checksum := sha512.Sum512_256(publicKey)
+
+	if !bytes.Equal(decoded[ed25519.PublicKeySize:], checksum[len(checksum)-4:]) {
+		return errors.New("crypto: Algorand address checksum is not valid")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return errors.New("crypto: Algorand signature must be 64 bytes encoded as base64")
+	}
+
+	if !ed25519.Verify(publicKey, []byte(algorandBytesPrefix+message), signature) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func algorandAddress(publicKey ed25519.PublicKey) string {
+	checksum := sha512.Sum512_256(publicKey)
+
+	return algorandAddressEncoding.EncodeToString(append(publicKey, checksum[len(checksum)-4:]...))
+}
+
+func TestVerifyAlgorandSignatureVectors(t *testing.T) {
+	// signBytes("hello") by the RFC 8032 test 1 key, whose public key
+	// d75a9801...f707511a has the Algorand address below. signBytes signs
+	// "MX" followed by the bytes.
+	address := "25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENMEF5QTE"
+	signature := "i0fHEihIM8bVre6wnSfX4EQ26p44lmU/+e5JnPsywqrJfIMn9ccdDvS4l8yavbNVdTbmTaMQsV7ZpfHRlp5tDg=="
+
+	require.NoError(t, VerifyAlgorandSignature("hello", signature, address))
+	require.ErrorIs(t, VerifyAlgorandSignature("hello!", signature, address), ErrSignatureMismatch)
+
+	// published addresses: the zero address, and the MainNet fee sink and
+	// rewards pool. Their checksums are valid, so only the signature fails.
+	for _, address := range []string{
+		"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ",
+		"Y76M3MSY6DKBRHBL7C3NNDXGS5IIMQVQVUAB6MP4XEMMGVF2QWNPL226CA",
+		"737777777777777777777777777777777777777777777777777UFEJ2CI",
+	} {
+		require.ErrorIs(t, VerifyAlgorandSignature("hello", signature, address), ErrSignatureMismatch, address)
+	}
+}
+
+func TestVerifyAlgorandSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	address := algorandAddress(publicKey)
+	require.Len(t, address, 58)
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("MXhello")))
+
+	require.NoError(t, VerifyAlgorandSignature("hello", signature, address))
+
+	// signed without the MX prefix
+	unprefixedSignature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("hello")))
+	require.ErrorIs(t, VerifyAlgorandSignature("hello", unprefixedSignature, address), ErrSignatureMismatch)
+
+	// different message
+	require.ErrorIs(t, VerifyAlgorandSignature("goodbye", signature, address), ErrSignatureMismatch)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// different address
+	require.ErrorIs(t, VerifyAlgorandSignature("hello", signature, algorandAddress(otherPublicKey)), ErrSignatureMismatch)
+
+	// bad checksum
+	badChecksum := []byte(address)
+	badChecksum[len(badChecksum)-1] = 'A'
+	if badChecksum[len(badChecksum)-2] == 'A' {
+		badChecksum[len(badChecksum)-2] = 'B'
+	} else {
+		badChecksum[len(badChecksum)-2] = 'A'
+	}
+
+	require.EqualError(t, VerifyAlgorandSignature("hello", signature, string(badChecksum)), "crypto: Algorand address checksum is not valid")
+
+	// not base32
+	require.EqualError(t, VerifyAlgorandSignature("hello", signature, "!!!"), "crypto: Algorand address is not valid")
+
+	// too short
+	require.EqualError(t, VerifyAlgorandSignature("hello", signature, address[:52]), "crypto: Algorand address is not valid")
+
+	// not base64
+	require.EqualError(t, VerifyAlgorandSignature("hello", "!!!", address), "crypto: Algorand signature must be 64 bytes encoded as base64")
+
+	// short signature
+	require.EqualError(t, VerifyAlgorandSignature("hello", "AQAB", address), "crypto: Algorand signature must be 64 bytes encoded as base64")
+}
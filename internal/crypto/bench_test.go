@@ -0,0 +1,94 @@
+//go:build bench
+
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+const benchmarkKey = "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"
+
+func benchmarkSizes() []int {
+	return []int{1024, 1024 * 1024}
+}
+
+func benchmarkSizeName(size int) string {
+	if size >= 1024*1024 {
+		return fmt.Sprintf("%dMB", size/(1024*1024))
+	}
+
+	return fmt.Sprintf("%dKB", size/1024)
+}
+
+func BenchmarkSecureToken(b *testing.B) {
+	for i := 0; i < b.N; i += 1 {
+		SecureToken()
+	}
+}
+
+func BenchmarkSecureAlphanumeric(b *testing.B) {
+	for i := 0; i < b.N; i += 1 {
+		SecureAlphanumeric(22)
+	}
+}
+
+func BenchmarkGenerateOtp(b *testing.B) {
+	for _, digits := range []int{4, 6} {
+		b.Run(fmt.Sprintf("%ddigit", digits), func(b *testing.B) {
+			for i := 0; i < b.N; i += 1 {
+				GenerateOtp(digits)
+			}
+		})
+	}
+}
+
+func BenchmarkNewEncryptedString(b *testing.B) {
+	for _, size := range benchmarkSizes() {
+		data := make([]byte, size)
+		must(rand.Read(data))
+
+		b.Run(benchmarkSizeName(size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportMetric(float64(len(data)), "bytes")
+
+			for i := 0; i < b.N; i += 1 {
+				must(NewEncryptedString("id", data, "key-id", benchmarkKey))
+			}
+		})
+	}
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	keys := map[string]string{
+		"key-id": benchmarkKey,
+	}
+
+	for _, size := range benchmarkSizes() {
+		data := make([]byte, size)
+		must(rand.Read(data))
+
+		es := must(NewEncryptedString("id", data, "key-id", benchmarkKey))
+
+		b.Run(benchmarkSizeName(size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportMetric(float64(len(data)), "bytes")
+
+			for i := 0; i < b.N; i += 1 {
+				must(es.Decrypt("id", keys))
+			}
+		})
+	}
+}
+
+func BenchmarkDeriveSymmetricKey(b *testing.B) {
+	es := &EncryptedString{
+		KeyID:     "key-id",
+		Algorithm: AlgorithmAESGCMHKDF,
+	}
+
+	for i := 0; i < b.N; i += 1 {
+		must(es.deriveSymmetricKey("id", benchmarkKey))
+	}
+}
@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStringBinary(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	examples := [][]EncryptedStringOption{
+		nil,
+		{WithKeyNamespace("payments"), WithPurpose("export"), WithCounter(7), WithCompression(CompressionGzip)},
+		{WithHKDFSalt()},
+		{WithAlgorithm(AlgorithmAESCBCHMACSHA256)},
+	}
+
+	for _, opts := range examples {
+		es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], opts...)
+		require.NoError(t, err)
+
+		data, err := es.MarshalBinary()
+		require.NoError(t, err)
+		assert.Less(t, len(data), len(es.String()))
+
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(es))
+
+		var dec EncryptedString
+		require.NoError(t, gob.NewDecoder(&buf).Decode(&dec))
+		assert.Equal(t, *es, dec)
+
+		decrypted, err := dec.Decrypt(id, map[string]string{
+			"key-id":          keys["key-id"],
+			"payments/key-id": keys["key-id"],
+		}, opts...)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), decrypted)
+	}
+
+	// values written before CreatedAt was introduced keep a zero time
+	legacy := ParseEncryptedString(`{"key_id":"key-id","alg":"aes-gcm-hkdf","data":"ZgijZYbAqFsMWc2+XDUn6+3Je7s=","nonce":"vploBjFT7dCxxsdX"}`)
+	require.NotNil(t, legacy)
+
+	var dec EncryptedString
+	require.NoError(t, dec.UnmarshalBinary(must(legacy.MarshalBinary())))
+	assert.Equal(t, *legacy, dec)
+	assert.True(t, dec.CreatedAt.IsZero())
+}
+
+func TestEncryptedStringBinaryNegative(t *testing.T) {
+	es, err := NewEncryptedString(uuid.Must(uuid.NewV4()).String(), []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	require.NoError(t, err)
+
+	data := must(es.MarshalBinary())
+
+	future := *es
+	future.Version = EncryptedStringVersion + 1
+
+	unknown := *es
+	unknown.Algorithm = "different"
+
+	missing := *es
+	missing.KeyID = ""
+
+	examples := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, ErrMalformedBinary},
+		{[]byte(es.String()), ErrMalformedBinary},
+		{[]byte{binaryFormat + 1}, ErrMalformedBinary},
+		{data[:1], ErrMalformedBinary},
+		{data[:len(data)-1], ErrMalformedBinary},
+		{append(bytes.Clone(data), 0), ErrMalformedBinary},
+		// a field longer than the remaining data
+		{binary.AppendUvarint(bytes.Clone(data[:len(data)-2]), 100), ErrMalformedBinary},
+		// a varint that overflows 64 bits
+		{append([]byte{binaryFormat}, bytes.Repeat([]byte{0xff}, 10)...), ErrMalformedBinary},
+		{must(future.MarshalBinary()), ErrUnsupportedVersion},
+		{must(unknown.MarshalBinary()), ErrUnsupportedAlgorithm},
+		{must(missing.MarshalBinary()), ErrInvalidEncryptedString},
+	}
+
+	for _, example := range examples {
+		dec := EncryptedString{KeyID: "unchanged"}
+		assert.ErrorIs(t, dec.UnmarshalBinary(example.data), example.err, example.data)
+		assert.Equal(t, "unchanged", dec.KeyID)
+	}
+}
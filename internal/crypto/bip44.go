@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrWrongCoinType is returned by ValidateBIP44Path when the coin type in
+// the derivation path does not belong to the chain.
+var ErrWrongCoinType = errors.New("crypto: BIP-44 path coin type does not match chain")
+
+// coinTypeRegistry maps chains to their SLIP-0044 coin types.
+var coinTypeRegistry = map[string]uint32{
+	"bitcoin":  0,
+	"ethereum": 60,
+	"cosmos":   118,
+	"solana":   501,
+}
+
+// bip44HardenedLevels is the number of leading BIP-44 levels (purpose, coin
+// type and account) that must use hardened derivation.
+const bip44HardenedLevels = 3
+
+// ValidateBIP44Path checks that a BIP-44 derivation path, such as
+// m/44'/60'/0'/0/0, is well formed and uses the SLIP-0044 coin type
+// registered for the chain. This prevents keys derived for one chain
+// from being used on another. Each index may carry a single ' or h
+// hardened marker, and the purpose, coin type and account levels must be
+// hardened.
+func ValidateBIP44Path(path string, chain string) error {
+	expectedCoinType, ok := coinTypeRegistry[chain]
+	if !ok {
+		return fmt.Errorf("crypto: no SLIP-0044 coin type registered for chain %q", chain)
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) < 3 || segments[0] != "m" {
+		return fmt.Errorf("crypto: BIP-44 path %q is not valid", path)
+	}
+
+	levels := make([]uint32, len(segments)-1)
+
+	for i, segment := range segments[1:] {
+		hardened := strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "h")
+		if hardened {
+			segment = segment[:len(segment)-1]
+		}
+
+		if !hardened && i < bip44HardenedLevels {
+			return fmt.Errorf("crypto: BIP-44 path %q must be hardened at level %d", path, i)
+		}
+
+		index, err := strconv.ParseUint(segment, 10, 31)
+		if err != nil {
+			return fmt.Errorf("crypto: BIP-44 path %q has invalid index at level %d", path, i)
+		}
+
+		levels[i] = uint32(index)
+	}
+
+	if levels[0] != 44 {
+		return fmt.Errorf("crypto: BIP-44 path %q does not use purpose 44", path)
+	}
+
+	if levels[1] != expectedCoinType {
+		return fmt.Errorf("%w: %q uses coin type %d, expected %d for %s", ErrWrongCoinType, path, levels[1], expectedCoinType, chain)
+	}
+
+	return nil
+}
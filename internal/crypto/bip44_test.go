@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBIP44Path(t *testing.T) {
+	positiveExamples := []struct {
+		path  string
+		chain string
+	}{
+		{"m/44'/60'/0'/0/0", "ethereum"},
+		{"m/44'/501'/0'/0'", "solana"},
+		{"m/44'/0'/0'/0/1", "bitcoin"},
+		{"m/44h/118h/0h/0/0", "cosmos"},
+		{"m/44'/60'", "ethereum"},
+	}
+
+	for _, example := range positiveExamples {
+		require.NoError(t, ValidateBIP44Path(example.path, example.chain), example.path)
+	}
+
+	wrongCoinTypeExamples := []struct {
+		path  string
+		chain string
+	}{
+		{"m/44'/60'/0'/0/0", "solana"},
+		{"m/44'/501'/0'/0'", "ethereum"},
+		{"m/44'/118'/0'/0/0", "bitcoin"},
+	}
+
+	for _, example := range wrongCoinTypeExamples {
+		require.ErrorIs(t, ValidateBIP44Path(example.path, example.chain), ErrWrongCoinType, example.path)
+	}
+
+	negativeExamples := []struct {
+		path  string
+		chain string
+	}{
+		{"m/44'/60'/0'/0/0", "unknown"},
+		{"", "ethereum"},
+		{"m/44'", "ethereum"},
+		{"x/44'/60'/0'/0/0", "ethereum"},
+		{"m/44'/sixty'/0'/0/0", "ethereum"},
+		{"m/44'/60'/2147483648/0/0", "ethereum"},
+		{"m/49'/60'/0'/0/0", "ethereum"},
+		{"m/44'h/60'/0'/0/0", "ethereum"},
+		{"m/44''/60'/0'/0/0", "ethereum"},
+		{"m/44'/60hh/0'/0/0", "ethereum"},
+		{"m/44/60'/0'/0/0", "ethereum"},
+		{"m/44'/60/0'/0/0", "ethereum"},
+		{"m/44'/60'/0/0/0", "ethereum"},
+	}
+
+	for _, example := range negativeExamples {
+		err := ValidateBIP44Path(example.path, example.chain)
+		require.Error(t, err, example.path)
+		require.NotErrorIs(t, err, ErrWrongCoinType, example.path)
+	}
+}
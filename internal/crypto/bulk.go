@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"errors"
+	"sync"
+)
+
+// BulkDecryptRecord is a single value to decrypt with BulkDecryptParallel.
+// ID is the ID of the object the encrypted string belongs to.
+type BulkDecryptRecord struct {
+	ID        string
+	Encrypted *EncryptedString
+}
+
+// BulkDecryptResult holds the outcome of decrypting a BulkDecryptRecord.
+type BulkDecryptResult struct {
+	ID   string
+	Data []byte
+	Err  error
+}
+
+// BulkDecryptParallel decrypts the records using concurrency goroutines,
+// which is useful for data migrations such as key rotation where a large
+// number of rows need to be processed. Results are returned in the same
+// order as the records. A failure to decrypt a record is reported in its
+// result and does not stop the rest of the batch.
+func BulkDecryptParallel(records []BulkDecryptRecord, keys map[string]string, concurrency int, opts ...EncryptedStringOption) ([]BulkDecryptResult, error) {
+	if concurrency < 1 {
+		return nil, errors.New("crypto: bulk decrypt concurrency must be at least 1")
+	}
+
+	results := make([]BulkDecryptResult, len(records))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i += 1 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range work {
+				record := records[index]
+				result := &results[index]
+
+				result.ID = record.ID
+
+				if record.Encrypted == nil {
+					result.Err = errors.New("crypto: bulk decrypt record has no encrypted string")
+					continue
+				}
+
+				result.Data, result.Err = record.Encrypted.Decrypt(record.ID, keys, opts...)
+			}
+		}()
+	}
+
+	for i := range records {
+		work <- i
+	}
+
+	close(work)
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkDecryptParallel(t *testing.T) {
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	var records []BulkDecryptRecord
+
+	for i := 0; i < 50; i += 1 {
+		id := uuid.Must(uuid.NewV4()).String()
+
+		es, err := NewEncryptedString(id, []byte(fmt.Sprintf("data-%d", i)), "key-id", keys["key-id"])
+		require.NoError(t, err)
+
+		records = append(records, BulkDecryptRecord{
+			ID:        id,
+			Encrypted: es,
+		})
+	}
+
+	// encrypted for a different ID
+	records[10].ID = uuid.Must(uuid.NewV4()).String()
+
+	// missing encrypted string
+	records[20].Encrypted = nil
+
+	results, err := BulkDecryptParallel(records, keys, 4)
+	require.NoError(t, err)
+	require.Len(t, results, len(records))
+
+	for i, result := range results {
+		require.Equal(t, records[i].ID, result.ID)
+
+		if i == 10 || i == 20 {
+			require.Error(t, result.Err)
+			require.Nil(t, result.Data)
+		} else {
+			require.NoError(t, result.Err)
+			require.Equal(t, []byte(fmt.Sprintf("data-%d", i)), result.Data)
+		}
+	}
+
+	_, err = BulkDecryptParallel(records, keys, 0)
+	require.Error(t, err)
+}
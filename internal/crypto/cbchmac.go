@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// cbcHMACKeySize is the size of the derived key for AES-CBC-HMAC, split
+// into a 256 bit MAC key and a 256 bit AES key as in RFC 7518, 5.2.
+const cbcHMACKeySize = 512 / 8
+
+var errCBCHMACOpen = errors.New("crypto: message authentication failed")
+
+// cbcHMAC implements cipher.AEAD with AES-CBC and PKCS #7 padding,
+// authenticated with HMAC-SHA256 over the additional data, IV, ciphertext
+// and additional data length, following the construction of RFC 7518,
+// 5.2.2. The full 256 bit tag is appended to the ciphertext.
+type cbcHMAC struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+func newCBCHMAC(key []byte) cipher.AEAD {
+	return &cbcHMAC{
+		block:  must(aes.NewCipher(key[32:])),
+		macKey: key[:32],
+	}
+}
+
+func (c *cbcHMAC) NonceSize() int {
+	return aes.BlockSize
+}
+
+func (c *cbcHMAC) Overhead() int {
+	return aes.BlockSize + sha256.Size
+}
+
+func (c *cbcHMAC) tag(nonce, ciphertext, additionalData []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+
+	mac.Write(additionalData)
+	mac.Write(nonce)
+	mac.Write(ciphertext)
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(len(additionalData))*8))
+
+	return mac.Sum(nil)
+}
+
+func (c *cbcHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+
+	ciphertext := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(ciphertext, ciphertext)
+
+	dst = append(dst, ciphertext...)
+
+	return append(dst, c.tag(nonce, ciphertext, additionalData)...)
+}
+
+func (c *cbcHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aes.BlockSize+sha256.Size {
+		return nil, errCBCHMACOpen
+	}
+
+	ciphertext, tag := ciphertext[:len(ciphertext)-sha256.Size], ciphertext[len(ciphertext)-sha256.Size:]
+
+	if !hmac.Equal(tag, c.tag(nonce, ciphertext, additionalData)) || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errCBCHMACOpen
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errCBCHMACOpen
+	}
+
+	return append(dst, plaintext[:len(plaintext)-padding]...), nil
+}
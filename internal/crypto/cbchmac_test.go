@@ -0,0 +1,104 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStringAESCBCHMAC(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	for _, data := range [][]byte{[]byte("data"), bytes.Repeat([]byte("a"), 16), bytes.Repeat([]byte("a"), 100)} {
+		es, err := NewEncryptedString(id, data, "key-id", keys["key-id"], WithAlgorithm(AlgorithmAESCBCHMACSHA256))
+		require.NoError(t, err)
+		assert.Equal(t, "aes-cbc-hmac-sha256", es.Algorithm)
+		assert.Len(t, es.Nonce, 16)
+		assert.Len(t, es.MAC, 32)
+		assert.Len(t, es.Data, (len(data)/16+1)*16)
+
+		dec, err := ParseEncryptedStringE(es.String())
+		require.NoError(t, err)
+		assert.Equal(t, es.MAC, dec.MAC)
+
+		decrypted, err := dec.Decrypt(id, keys)
+		require.NoError(t, err)
+		assert.Equal(t, data, decrypted)
+
+		// the MAC is verified
+		dec.MAC[0] ^= 1
+		_, err = dec.Decrypt(id, keys)
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+		dec.MAC[0] ^= 1
+
+		dec.Data[0] ^= 1
+		_, err = dec.Decrypt(id, keys)
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+		// the MAC is required
+		dec.MAC = nil
+		assert.False(t, dec.IsValid())
+		_, err = ParseEncryptedStringE(dec.String())
+		assert.ErrorIs(t, err, ErrInvalidEncryptedString)
+	}
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithAlgorithm(AlgorithmAESCBCHMACSHA256), WithHKDFHash(HKDFSHA384), WithFIPSMode())
+	require.NoError(t, err)
+	assert.Equal(t, "aes-cbc-hmac-sha256-sha384", es.Algorithm)
+
+	decrypted, err := es.Decrypt(id, keys, WithFIPSMode())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	_, err = NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithAlgorithm(AlgorithmAESCBCHMACSHA256), WithFIPSMode())
+	assert.ErrorIs(t, err, ErrAlgorithmNotFIPSCompliant)
+}
+
+func TestCBCHMACOpenNegative(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, cbcHMACKeySize)
+	nonce := bytes.Repeat([]byte{2}, aes.BlockSize)
+
+	aead := newCBCHMAC(key)
+	c := aead.(*cbcHMAC)
+
+	assert.Equal(t, 48, aead.Overhead())
+
+	sealed := aead.Seal(nil, nonce, []byte("data"), []byte("ad"))
+
+	opened, err := aead.Open(nil, nonce, sealed, []byte("ad"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), opened)
+
+	// wrong additional data
+	_, err = aead.Open(nil, nonce, sealed, []byte("other"))
+	assert.Error(t, err)
+
+	// too short
+	_, err = aead.Open(nil, nonce, sealed[:40], []byte("ad"))
+	assert.Error(t, err)
+
+	// authenticated, but not a multiple of the block size
+	ciphertext := bytes.Repeat([]byte{3}, aes.BlockSize+1)
+	_, err = aead.Open(nil, nonce, append(ciphertext, c.tag(nonce, ciphertext, nil)...), nil)
+	assert.Error(t, err)
+
+	// authenticated, but invalid padding
+	for _, padding := range []byte{0, 17, 4} {
+		plaintext := bytes.Repeat([]byte{5}, aes.BlockSize)
+		plaintext[aes.BlockSize-1] = padding
+
+		ciphertext := make([]byte, aes.BlockSize)
+		cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(ciphertext, plaintext)
+
+		_, err = aead.Open(nil, nonce, append(ciphertext, c.tag(nonce, ciphertext, nil)...), nil)
+		assert.Error(t, err, padding)
+	}
+}
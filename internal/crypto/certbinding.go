@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+)
+
+// ErrTokenCertBindingMismatch is returned by VerifyTokenCertBinding when the
+// token is not bound to the presented certificate.
+var ErrTokenCertBindingMismatch = errors.New("crypto: token is not bound to the certificate")
+
+// certificateThumbprint returns the base64url encoded SHA-256 hash of the
+// DER encoding of a PEM certificate, as used in the x5t#S256 confirmation
+// method.
+func certificateThumbprint(certPEM string) (string, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", errors.New("crypto: certificate is not PEM encoded")
+	}
+
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return "", err
+	}
+
+	thumbprint := sha256.Sum256(block.Bytes)
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:]), nil
+}
+
+// BindTokenToCertificate returns a copy of the JWT claims with the
+// cnf.x5t#S256 confirmation claim set to the thumbprint of the client
+// certificate, as defined in RFC 8705 for certificate-bound access tokens.
+// Other members of an existing cnf claim are kept.
+func BindTokenToCertificate(jwtClaims map[string]interface{}, certPEM string) (map[string]interface{}, error) {
+	thumbprint, err := certificateThumbprint(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]interface{}, len(jwtClaims)+1)
+	for name, value := range jwtClaims {
+		claims[name] = value
+	}
+
+	cnf := make(map[string]interface{})
+	if existing, ok := jwtClaims["cnf"].(map[string]interface{}); ok {
+		for name, value := range existing {
+			cnf[name] = value
+		}
+	}
+
+	cnf["x5t#S256"] = thumbprint
+	claims["cnf"] = cnf
+
+	return claims, nil
+}
+
+// VerifyTokenCertBinding checks that the JWT claims are bound to the client
+// certificate presented over mTLS.
+func VerifyTokenCertBinding(claims map[string]interface{}, certPEM string) error {
+	thumbprint, err := certificateThumbprint(certPEM)
+	if err != nil {
+		return err
+	}
+
+	cnf, _ := claims["cnf"].(map[string]interface{})
+	bound, _ := cnf["x5t#S256"].(string)
+
+	if !SecureCompareString(bound, thumbprint) {
+		return ErrTokenCertBindingMismatch
+	}
+
+	return nil
+}
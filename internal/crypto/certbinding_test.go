@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestCertificate(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestTokenCertBinding(t *testing.T) {
+	certPEM := generateTestCertificate(t)
+	otherCertPEM := generateTestCertificate(t)
+
+	claims := map[string]interface{}{
+		"sub": "user",
+		"cnf": map[string]interface{}{
+			"jkt": "thumbprint",
+		},
+	}
+
+	bound, err := BindTokenToCertificate(claims, certPEM)
+	require.NoError(t, err)
+	require.Equal(t, "user", bound["sub"])
+
+	cnf := bound["cnf"].(map[string]interface{})
+	require.Equal(t, "thumbprint", cnf["jkt"])
+	require.Len(t, cnf["x5t#S256"], 43)
+
+	// original claims are untouched
+	require.NotContains(t, claims["cnf"], "x5t#S256")
+
+	require.NoError(t, VerifyTokenCertBinding(bound, certPEM))
+	require.ErrorIs(t, VerifyTokenCertBinding(bound, otherCertPEM), ErrTokenCertBindingMismatch)
+	require.ErrorIs(t, VerifyTokenCertBinding(claims, certPEM), ErrTokenCertBindingMismatch)
+	require.ErrorIs(t, VerifyTokenCertBinding(map[string]interface{}{}, certPEM), ErrTokenCertBindingMismatch)
+
+	bound, err = BindTokenToCertificate(map[string]interface{}{}, certPEM)
+	require.NoError(t, err)
+	require.NoError(t, VerifyTokenCertBinding(bound, certPEM))
+
+	// not PEM
+	_, err = BindTokenToCertificate(claims, "not-a-certificate")
+	require.Error(t, err)
+
+	require.Error(t, VerifyTokenCertBinding(bound, "not-a-certificate"))
+
+	// not a certificate
+	_, err = BindTokenToCertificate(claims, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})))
+	require.Error(t, err)
+
+	// invalid DER
+	_, err = BindTokenToCertificate(claims, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("cert")})))
+	require.Error(t, err)
+}
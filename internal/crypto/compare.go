@@ -0,0 +1,15 @@
+package crypto
+
+import "crypto/subtle"
+
+// SecureCompareBytes tells you if a and b are equal. The comparison takes
+// the same time for any contents of equal length, so it doesn't leak how
+// much of a secret was guessed correctly.
+func SecureCompareBytes(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// SecureCompareString is SecureCompareBytes for strings.
+func SecureCompareString(a, b string) bool {
+	return SecureCompareBytes([]byte(a), []byte(b))
+}
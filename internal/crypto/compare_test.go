@@ -0,0 +1,17 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureCompare(t *testing.T) {
+	assert.True(t, SecureCompareBytes([]byte("token"), []byte("token")))
+	assert.False(t, SecureCompareBytes([]byte("token"), []byte("tokem")))
+	assert.False(t, SecureCompareBytes([]byte("token"), []byte("token2")))
+
+	assert.True(t, SecureCompareString("", ""))
+	assert.True(t, SecureCompareString(GenerateTokenHash("user@example.com", "123456"), GenerateTokenHash("user@example.com", "123456")))
+	assert.False(t, SecureCompareString(GenerateTokenHash("user@example.com", "123456"), GenerateTokenHash("user@example.com", "123457")))
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CompressionAlgo is a compression algorithm applied to data before it is
+// encrypted.
+type CompressionAlgo string
+
+const (
+	CompressionNone CompressionAlgo = ""
+	CompressionGzip CompressionAlgo = "gzip"
+)
+
+// MaxDecompressedSize is the largest plaintext, in bytes, that compressed
+// data may expand to when decrypted. It stops a small gzip payload from
+// exhausting memory.
+const MaxDecompressedSize = 16 << 20
+
+// ErrDecompressedTooLarge is returned when compressed data expands beyond
+// MaxDecompressedSize.
+var ErrDecompressedTooLarge = errors.New("crypto: decompressed data is too large")
+
+func compress(algo CompressionAlgo, data []byte) ([]byte, error) {
+	switch algo {
+	case CompressionNone:
+		return data, nil
+
+	case CompressionGzip:
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+		must(w.Write(data))
+		must(0, w.Close())
+
+		return buf.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("crypto: unsupported compression algorithm %q", algo)
+}
+
+func decompress(algo CompressionAlgo, data []byte) ([]byte, error) {
+	switch algo {
+	case CompressionNone:
+		return data, nil
+
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(out) > MaxDecompressedSize {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrDecompressedTooLarge, MaxDecompressedSize)
+		}
+
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("crypto: unsupported compression algorithm %q", algo)
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStringCompression(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	data := bytes.Repeat([]byte("a long and repetitive user bio. "), 10*1024/32)
+
+	es, err := NewEncryptedString(id, data, "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithCompression(CompressionGzip))
+	require.NoError(t, err)
+
+	assert.Equal(t, "gzip", es.Compression)
+	assert.Less(t, len(es.Data), 1024)
+
+	dec := ParseEncryptedString(es.String())
+	require.NotNil(t, dec)
+
+	decrypted, err := dec.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	// compression is authenticated, so it can't be stripped
+	dec.Compression = ""
+
+	_, err = dec.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+	assert.Error(t, err)
+
+	es, err = NewEncryptedString(id, data, "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	require.NoError(t, err)
+	assert.NotContains(t, es.String(), "compression")
+
+	_, err = NewEncryptedString(id, data, "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithCompression("zstd"))
+	assert.EqualError(t, err, `crypto: unsupported compression algorithm "zstd"`)
+}
+
+func TestDecompress(t *testing.T) {
+	_, err := decompress(CompressionGzip, []byte("not gzip"))
+	assert.Error(t, err)
+
+	compressed := must(compress(CompressionGzip, []byte("data")))
+
+	_, err = decompress(CompressionGzip, compressed[:len(compressed)-4])
+	assert.Error(t, err)
+
+	data, err := decompress(CompressionGzip, must(compress(CompressionGzip, make([]byte, MaxDecompressedSize))))
+	assert.NoError(t, err)
+	assert.Len(t, data, MaxDecompressedSize)
+
+	_, err = decompress(CompressionGzip, must(compress(CompressionGzip, make([]byte, MaxDecompressedSize+1))))
+	assert.ErrorIs(t, err, ErrDecompressedTooLarge)
+
+	_, err = decompress("zstd", compressed)
+	assert.EqualError(t, err, `crypto: unsupported compression algorithm "zstd"`)
+}
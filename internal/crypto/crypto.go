@@ -1,20 +1,46 @@
+// Package crypto implements the encryption, hashing and random token
+// primitives used by Auth.
+//
+// Encrypted strings are stored as JSON with a "version" field:
+//
+//	version  layout                                  read by
+//	1        key derived with a nil HKDF salt        all releases
+//	2        random HKDF salt stored in "salt"       releases since version 2
+//
+// Values written before the field was introduced have no version and are
+// read as version 1. Releases refuse to decrypt versions newer than
+// EncryptedStringVersion, so a new layout must bump it. Rolling back to a
+// release that predates a version leaves values written in that version
+// unreadable, so a new version is only written behind an option, such as
+// WithHKDFSalt for version 2, once every instance can read it.
+//
+// Tokens, token hashes and other secrets must be compared with
+// SecureCompareString or SecureCompareBytes rather than ==, which can leak
+// through timing how much of the value matched.
 package crypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base32"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
 
@@ -29,8 +55,57 @@ func GenerateOtp(digits int) string {
 
 	return otp
 }
+
+// GenerateOtpChannels generates an independent random n digit otp for each
+// of the delivery channels.
+func GenerateOtpChannels(digits int, channels int) []string {
+	otps := make([]string, channels)
+
+	for i := range otps {
+		otps[i] = GenerateOtp(digits)
+	}
+
+	return otps
+}
+
+// GenerateSingleOtpMultipleTokenHashes hashes the same otp for each email
+// or phone, so that a code sent to several channels can be verified
+// against any of them.
+func GenerateSingleOtpMultipleTokenHashes(emailOrPhone []string, otp string) []string {
+	hashes := make([]string, len(emailOrPhone))
+
+	for i, identifier := range emailOrPhone {
+		hashes[i] = GenerateTokenHash(identifier, otp)
+	}
+
+	return hashes
+}
+
+// ErrUnknownHashAlgorithm is returned by GenerateTokenHashAlgo when the hash
+// algorithm is not one of sha224, sha256 or sha512.
+var ErrUnknownHashAlgorithm = errors.New("crypto: unknown token hash algorithm")
+
+// GenerateTokenHash hashes the email or phone and OTP with SHA-224. This is
+// the format used for the token hashes stored in the database.
 func GenerateTokenHash(emailOrPhone, otp string) string {
-	return fmt.Sprintf("%x", sha256.Sum224([]byte(emailOrPhone+otp)))
+	return must(GenerateTokenHashAlgo(emailOrPhone, otp, "sha224"))
+}
+
+// GenerateTokenHashAlgo is like GenerateTokenHash but lets the caller pick
+// the hash algorithm, one of sha224, sha256 or sha512.
+func GenerateTokenHashAlgo(emailOrPhone, otp, algorithm string) (string, error) {
+	data := []byte(emailOrPhone + otp)
+
+	switch algorithm {
+	case "sha224":
+		return fmt.Sprintf("%x", sha256.Sum224(data)), nil
+	case "sha256":
+		return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+	case "sha512":
+		return fmt.Sprintf("%x", sha512.Sum512(data)), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownHashAlgorithm, algorithm)
 }
 
 // Generated a random secure integer from [0, max[
@@ -39,77 +114,541 @@ func secureRandomInt(max int) int {
 	return int(randomInt.Int64())
 }
 
+// Algorithms supported by EncryptedString.
+const (
+	AlgorithmAESGCMHKDF            = "aes-gcm-hkdf"
+	AlgorithmChaCha20Poly1305HKDF  = "chacha20-poly1305-hkdf"
+	AlgorithmXChaCha20Poly1305HKDF = "xchacha20poly1305-hkdf"
+
+	// AlgorithmAESCBCHMACSHA256 is AES-256-CBC authenticated with
+	// HMAC-SHA256, for when AES-GCM can't be used. The MAC is stored
+	// separately from the data.
+	AlgorithmAESCBCHMACSHA256 = "aes-cbc-hmac-sha256"
+
+	// AlgorithmRSAOAEPSHA256 encrypts to an RSA public key, see
+	// NewEncryptedStringRSA.
+	AlgorithmRSAOAEPSHA256 = "rsa-oaep-sha256"
+)
+
+// algorithmNonceSizes holds the nonce size of each supported algorithm.
+var algorithmNonceSizes = map[string]int{
+	AlgorithmAESGCMHKDF:            12,
+	AlgorithmChaCha20Poly1305HKDF:  chacha20poly1305.NonceSize,
+	AlgorithmXChaCha20Poly1305HKDF: chacha20poly1305.NonceSizeX,
+	AlgorithmAESCBCHMACSHA256:      16,
+	AlgorithmRSAOAEPSHA256:         0,
+}
+
+// HKDFHashAlgorithm is the hash function used to derive the symmetric key of
+// an EncryptedString.
+type HKDFHashAlgorithm string
+
+const (
+	HKDFSHA256 HKDFHashAlgorithm = "sha256"
+	HKDFSHA384 HKDFHashAlgorithm = "sha384"
+	HKDFSHA512 HKDFHashAlgorithm = "sha512"
+)
+
+var hkdfHashes = map[HKDFHashAlgorithm]func() hash.Hash{
+	HKDFSHA256: sha256.New,
+	HKDFSHA384: sha512.New384,
+	HKDFSHA512: sha512.New,
+}
+
+// splitAlgorithm splits an algorithm name into the cipher algorithm and the
+// HKDF hash. SHA-256 is not part of the name, so that values encrypted
+// before the hash was configurable keep working. Other hashes are appended
+// to the name, e.g. "aes-gcm-hkdf-sha384".
+func splitAlgorithm(algorithm string) (string, HKDFHashAlgorithm) {
+	for _, hkdfHash := range []HKDFHashAlgorithm{HKDFSHA384, HKDFSHA512} {
+		if cipherAlgorithm, ok := strings.CutSuffix(algorithm, "-"+string(hkdfHash)); ok {
+			return cipherAlgorithm, hkdfHash
+		}
+	}
+
+	return algorithm, HKDFSHA256
+}
+
+var (
+	// ErrInvalidNonceLength is returned when decrypting an EncryptedString whose
+	// nonce length does not match its algorithm.
+	ErrInvalidNonceLength = errors.New("crypto: encrypted string nonce length does not match algorithm")
+
+	// ErrKeyNotFound is returned when decrypting an EncryptedString whose key
+	// ID is not among the decryption keys.
+	ErrKeyNotFound = errors.New("crypto: decryption key does not exist")
+
+	// ErrDecryptionFailed is returned when an EncryptedString can't be
+	// authenticated, usually because the wrong key or ID was used or the
+	// value was tampered with.
+	ErrDecryptionFailed = errors.New("crypto: decryption failed")
+
+	// ErrNotEncrypted is returned by ParseEncryptedStringE when the string
+	// is not in the encrypted string format at all.
+	ErrNotEncrypted = errors.New("crypto: string is not an encrypted string")
+
+	// ErrMalformedJSON is returned by ParseEncryptedStringE when the string
+	// looks like an encrypted string but is not valid JSON.
+	ErrMalformedJSON = errors.New("crypto: encrypted string is not valid JSON")
+
+	// ErrMalformedBinary is returned by UnmarshalBinary when the data is
+	// not in the binary encoding written by MarshalBinary.
+	ErrMalformedBinary = errors.New("crypto: encrypted string has a malformed binary encoding")
+
+	// ErrUnsupportedAlgorithm is returned by ParseEncryptedStringE when the
+	// encrypted string uses an unknown algorithm.
+	ErrUnsupportedAlgorithm = errors.New("crypto: encrypted string uses an unsupported algorithm")
+
+	// ErrInvalidEncryptedString is returned by ParseEncryptedStringE when
+	// the key ID or data is missing, or the nonce has the wrong length.
+	ErrInvalidEncryptedString = errors.New("crypto: encrypted string is missing fields or has an invalid nonce")
+
+	// ErrUnsupportedVersion is returned by ParseEncryptedStringE and
+	// Decrypt when the encrypted string uses an unknown format version.
+	ErrUnsupportedVersion = errors.New("crypto: encrypted string uses an unsupported version")
+
+	// ErrAlgorithmNotFIPSCompliant is returned in FIPS mode when an
+	// algorithm other than AES-GCM or AES-CBC-HMAC with a SHA-384 or
+	// SHA-512 HKDF, or RSA-OAEP, is used.
+	ErrAlgorithmNotFIPSCompliant = errors.New("crypto: algorithm is not allowed in FIPS mode")
+)
+
+// EncryptedStringVersion is the newest format version that can be
+// decrypted. NewEncryptedString writes version 1 unless WithHKDFSalt is
+// used.
+const EncryptedStringVersion = 2
+
+const (
+	encryptedStringVersionNilSalt = 1
+	encryptedStringVersionSalted  = 2
+)
+
 type EncryptedString struct {
-	KeyID     string `json:"key_id"`
-	Algorithm string `json:"alg"`
-	Data      []byte `json:"data"`
-	Nonce     []byte `json:"nonce,omitempty"`
+	// Version is the format version of the encrypted string. Values
+	// written before versioning was introduced have a zero Version,
+	// which is treated as version 1.
+	Version int `json:"version,omitempty"`
+
+	KeyID        string `json:"key_id"`
+	KeyNamespace string `json:"key_namespace,omitempty"`
+	Algorithm    string `json:"alg"`
+	Data         []byte `json:"data"`
+	Nonce        []byte `json:"nonce,omitempty"`
+	Counter      uint64 `json:"counter,omitempty"`
+	Compression  string `json:"compression,omitempty"`
+	Purpose      string `json:"purpose,omitempty"`
+
+	// Salt is the HKDF salt used to derive the symmetric key. Values
+	// encrypted before it was introduced have no salt and derive their
+	// key with a nil salt.
+	Salt []byte `json:"salt,omitempty"`
+
+	// MAC is the HMAC of algorithms that don't include the tag in the
+	// data, such as AlgorithmAESCBCHMACSHA256.
+	MAC []byte `json:"mac,omitempty"`
+
+	// CreatedAt is when the value was encrypted. It is not authenticated
+	// and only meant for planning key rotation. Values encrypted before it
+	// was introduced have a zero CreatedAt, which is left out when
+	// serialized so they round-trip unchanged.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// EncryptedStringOption configures how an EncryptedString is encrypted or
+// decrypted.
+type EncryptedStringOption func(*encryptedStringOptions)
+
+type encryptedStringOptions struct {
+	keyNamespace string
+	algorithm    string
+	counter      uint64
+	compression  CompressionAlgo
+	hkdfHash     HKDFHashAlgorithm
+	fips         bool
+	purpose      string
+	hkdfSalt     bool
+}
+
+func newEncryptedStringOptions(opts []EncryptedStringOption) *encryptedStringOptions {
+	options := &encryptedStringOptions{
+		algorithm: AlgorithmAESGCMHKDF,
+		hkdfHash:  HKDFSHA256,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// algorithmName returns the full name of the algorithm selected by the
+// options, including the HKDF hash.
+func (o *encryptedStringOptions) algorithmName() string {
+	if o.hkdfHash == HKDFSHA256 {
+		return o.algorithm
+	}
+
+	return o.algorithm + "-" + string(o.hkdfHash)
+}
+
+// checkFIPS returns an error if FIPS mode is enabled and the algorithm is
+// not AES-GCM or AES-CBC-HMAC with a SHA-384 or SHA-512 HKDF, or RSA-OAEP.
+func (o *encryptedStringOptions) checkFIPS(algorithm string) error {
+	if !o.fips || algorithm == AlgorithmRSAOAEPSHA256 {
+		return nil
+	}
+
+	cipherAlgorithm, hkdfHash := splitAlgorithm(algorithm)
+	if (cipherAlgorithm != AlgorithmAESGCMHKDF && cipherAlgorithm != AlgorithmAESCBCHMACSHA256) || hkdfHash == HKDFSHA256 {
+		return fmt.Errorf("%w: %q", ErrAlgorithmNotFIPSCompliant, algorithm)
+	}
+
+	return nil
+}
+
+// WithKeyNamespace scopes the key ID to a namespace, such as the name of the
+// service owning the data. The namespace is prepended to the key ID when
+// looking up decryption keys (e.g. "payments/main-key") and is bound to the
+// ciphertext, so values encrypted in one namespace can't be decrypted in
+// another even when the key material is shared.
+func WithKeyNamespace(namespace string) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.keyNamespace = namespace
+	}
+}
+
+// WithAlgorithm selects the encryption algorithm used by NewEncryptedString.
+// Defaults to AlgorithmAESGCMHKDF.
+func WithAlgorithm(algorithm string) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.algorithm = algorithm
+	}
+}
+
+// WithHKDFHash selects the hash function used to derive the symmetric key.
+// Defaults to HKDFSHA256.
+func WithHKDFHash(algo HKDFHashAlgorithm) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.hkdfHash = algo
+	}
+}
+
+// WithFIPSMode only allows AES-GCM or AES-CBC-HMAC with a SHA-384 or
+// SHA-512 HKDF and RSA-OAEP, both when encrypting and decrypting. SHA-256 HKDF and ChaCha20
+// based algorithms are rejected with ErrAlgorithmNotFIPSCompliant.
+func WithFIPSMode() EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.fips = true
+	}
+}
+
+// WithCounter mixes an update counter of the object into the key derivation,
+// so each version of a frequently updated value is encrypted with a different
+// derived key. Callers should increment the counter on every update.
+func WithCounter(counter uint64) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.counter = counter
+	}
+}
+
+// WithCompression compresses the data before it is encrypted, which helps
+// with long user-generated content. Decrypt detects the compression used
+// from the encrypted string, so this option is not needed when decrypting.
+func WithCompression(algo CompressionAlgo) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.compression = algo
+	}
+}
+
+// WithPurpose binds the derived key to a purpose, such as "refresh_token"
+// or "mfa_backup", so a value encrypted for one purpose can't be decrypted
+// for another even when the object ID and key are the same. The same
+// purpose must be passed when decrypting.
+func WithPurpose(purpose string) EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.purpose = purpose
+	}
+}
+
+// WithHKDFSalt derives the key with a random 32 byte HKDF salt, which is
+// stored in the encrypted string. Salted values are written as version 2,
+// which releases before version 2 can't decrypt, so only enable it once
+// every instance reads version 2. Decrypt uses the stored salt, so this
+// option is not needed when decrypting.
+func WithHKDFSalt() EncryptedStringOption {
+	return func(o *encryptedStringOptions) {
+		o.hkdfSalt = true
+	}
+}
+
+// qualifiedKeyID returns the key ID prefixed with the key namespace, if any.
+func (es *EncryptedString) qualifiedKeyID() string {
+	if es.KeyNamespace == "" {
+		return es.KeyID
+	}
+
+	return es.KeyNamespace + "/" + es.KeyID
+}
+
+// additionalData returns the data authenticated along with the ciphertext.
+// The compression algorithm is only included when set, so that values
+// encrypted without compression keep their original additional data.
+func (es *EncryptedString) additionalData() []byte {
+	if es.Compression == "" {
+		return []byte(es.KeyNamespace)
+	}
+
+	return []byte(es.KeyNamespace + "\x00" + es.Compression)
+}
+
+// version returns the format version, treating a missing version as 1.
+func (es *EncryptedString) version() int {
+	if es.Version == 0 {
+		return 1
+	}
+
+	return es.Version
+}
+
+// checkVersion returns ErrUnsupportedVersion if the encrypted string's
+// format version is not known.
+func (es *EncryptedString) checkVersion() error {
+	if version := es.version(); version < 1 || version > EncryptedStringVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	return nil
+}
+
+// hasSeparateMAC tells you if the algorithm stores its MAC in the MAC field
+// rather than at the end of the data.
+func (es *EncryptedString) hasSeparateMAC() bool {
+	cipherAlgorithm, _ := splitAlgorithm(es.Algorithm)
+
+	return cipherAlgorithm == AlgorithmAESCBCHMACSHA256
+}
+
+// sealed returns the data as produced by cipher.AEAD's Seal, with the MAC
+// appended if it's stored separately.
+func (es *EncryptedString) sealed() []byte {
+	if !es.hasSeparateMAC() {
+		return es.Data
+	}
+
+	return append(bytes.Clone(es.Data), es.MAC...)
+}
+
+// setSealed stores the output of cipher.AEAD's Seal, splitting off the MAC
+// if it's stored separately.
+func (es *EncryptedString) setSealed(sealed []byte) {
+	if !es.hasSeparateMAC() {
+		es.Data = sealed
+		return
+	}
+
+	es.Data, es.MAC = sealed[:len(sealed)-sha256.Size], sealed[len(sealed)-sha256.Size:]
 }
 
 func (es *EncryptedString) IsValid() bool {
-	return es.KeyID != "" && len(es.Data) > 0 && len(es.Nonce) > 0 && es.Algorithm == "aes-gcm-hkdf"
+	cipherAlgorithm, _ := splitAlgorithm(es.Algorithm)
+	nonceSize, ok := algorithmNonceSizes[cipherAlgorithm]
+
+	if es.hasSeparateMAC() && len(es.MAC) != sha256.Size {
+		return false
+	}
+
+	return ok && es.checkVersion() == nil && es.KeyID != "" && len(es.Data) > 0 && len(es.Nonce) == nonceSize
+}
+
+// newAEAD returns the cipher for the algorithm, keyed with the derived
+// symmetric key.
+func newAEAD(algorithm string, key []byte) (cipher.AEAD, error) {
+	cipherAlgorithm, _ := splitAlgorithm(algorithm)
+
+	switch cipherAlgorithm {
+	case AlgorithmAESGCMHKDF:
+		block := must(aes.NewCipher(key))
+		return must(cipher.NewGCM(block)), nil
+
+	case AlgorithmChaCha20Poly1305HKDF:
+		// ChaCha20-Poly1305 is faster than AES-GCM on hardware
+		// without AES acceleration, such as some ARM machines.
+		return must(chacha20poly1305.New(key)), nil
+
+	case AlgorithmXChaCha20Poly1305HKDF:
+		// XChaCha20-Poly1305 uses a 192-bit nonce, so random nonces are
+		// safe to use for far more encryptions under the same key
+		// than with AES-GCM.
+		return must(chacha20poly1305.NewX(key)), nil
+
+	case AlgorithmAESCBCHMACSHA256:
+		return newCBCHMAC(key), nil
+	}
+
+	return nil, fmt.Errorf("crypto: unsupported encryption algorithm %q", algorithm)
 }
 
 // ShouldReEncrypt tells you if the value encrypted needs to be encrypted again with a newer key.
-func (es *EncryptedString) ShouldReEncrypt(encryptionKeyID string) bool {
-	return es.KeyID != encryptionKeyID
+// Values encrypted with a different algorithm than the one selected by opts
+// (AES-GCM by default) also need to be encrypted again.
+func (es *EncryptedString) ShouldReEncrypt(encryptionKeyID string, opts ...EncryptedStringOption) bool {
+	options := newEncryptedStringOptions(opts)
+
+	return es.KeyID != encryptionKeyID || es.Algorithm != options.algorithmName()
 }
 
-func (es *EncryptedString) Decrypt(id string, decryptionKeys map[string]string) ([]byte, error) {
-	decryptionKey := decryptionKeys[es.KeyID]
+// Age returns how long ago the value was encrypted. Values without a
+// CreatedAt are treated as infinitely old.
+func (es *EncryptedString) Age() time.Duration {
+	if es.CreatedAt.IsZero() {
+		return math.MaxInt64
+	}
+
+	return time.Since(es.CreatedAt)
+}
+
+// IsOlderThan tells you if the value was encrypted more than d ago.
+func (es *EncryptedString) IsOlderThan(d time.Duration) bool {
+	return es.Age() > d
+}
+
+// ShouldRotate tells you if the value needs to be encrypted again, either
+// because it's not encrypted with the newest key or because it was
+// encrypted more than maxAge ago.
+func (es *EncryptedString) ShouldRotate(maxAge time.Duration, newKeyID string, opts ...EncryptedStringOption) bool {
+	return es.ShouldReEncrypt(newKeyID, opts...) || es.IsOlderThan(maxAge)
+}
+
+func (es *EncryptedString) Decrypt(id string, decryptionKeys map[string]string, opts ...EncryptedStringOption) ([]byte, error) {
+	options := newEncryptedStringOptions(opts)
+
+	if es.KeyNamespace != options.keyNamespace {
+		return nil, fmt.Errorf("crypto: encrypted string belongs to key namespace %q, expected %q", es.KeyNamespace, options.keyNamespace)
+	}
+
+	if err := es.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	if es.Purpose != options.purpose {
+		return nil, fmt.Errorf("crypto: encrypted string has purpose %q, expected %q", es.Purpose, options.purpose)
+	}
+
+	if err := options.checkFIPS(es.Algorithm); err != nil {
+		return nil, err
+	}
+
+	keyID := es.qualifiedKeyID()
+	decryptionKey := decryptionKeys[keyID]
 
 	if decryptionKey == "" {
-		return nil, fmt.Errorf("crypto: decryption key with name %q does not exist", es.KeyID)
+		return nil, fmt.Errorf("%w: key with name %q", ErrKeyNotFound, keyID)
+	}
+
+	if es.Algorithm == AlgorithmRSAOAEPSHA256 {
+		return es.decryptRSA(id, decryptionKey)
 	}
 
-	key, err := deriveSymmetricKey(id, es.KeyID, decryptionKey)
+	key, err := es.deriveSymmetricKey(id, decryptionKey)
 	if err != nil {
 		return nil, err
 	}
 
-	block := must(aes.NewCipher(key))
-	cipher := must(cipher.NewGCM(block))
-
-	decrypted, err := cipher.Open(nil, es.Nonce, es.Data, nil) // #nosec G407
+	cipher, err := newAEAD(es.Algorithm, key)
 	if err != nil {
 		return nil, err
 	}
 
-	return decrypted, nil
+	if len(es.Nonce) != cipher.NonceSize() {
+		return nil, fmt.Errorf("%w: got %d bytes, expected %d for %q", ErrInvalidNonceLength, len(es.Nonce), cipher.NonceSize(), es.Algorithm)
+	}
+
+	decrypted, err := cipher.Open(nil, es.Nonce, es.sealed(), es.additionalData()) // #nosec G407
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+
+	return decompress(CompressionAlgo(es.Compression), decrypted)
 }
 
+// ParseEncryptedString parses an encrypted string, returning nil if the
+// string is not a valid encrypted string.
 func ParseEncryptedString(str string) *EncryptedString {
-	if !strings.HasPrefix(str, "{") {
+	es, err := ParseEncryptedStringE(str)
+	if err != nil {
 		return nil
 	}
 
+	return es
+}
+
+// ParseEncryptedStringE is like ParseEncryptedString but returns an error
+// describing why the string could not be parsed.
+func ParseEncryptedStringE(str string) (*EncryptedString, error) {
+	if !strings.HasPrefix(str, "{") {
+		return nil, ErrNotEncrypted
+	}
+
 	var es EncryptedString
 
 	if err := json.Unmarshal([]byte(str), &es); err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: %w", ErrMalformedJSON, err)
+	}
+
+	if err := es.validate(); err != nil {
+		return nil, err
+	}
+
+	return &es, nil
+}
+
+// validate returns the error describing why a decoded encrypted string is
+// not valid, if any.
+func (es *EncryptedString) validate() error {
+	if err := es.checkVersion(); err != nil {
+		return err
+	}
+
+	cipherAlgorithm, _ := splitAlgorithm(es.Algorithm)
+	if _, ok := algorithmNonceSizes[cipherAlgorithm]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, es.Algorithm)
 	}
 
 	if !es.IsValid() {
-		return nil
+		return ErrInvalidEncryptedString
 	}
 
-	return &es
+	return nil
 }
 
 func (es *EncryptedString) String() string {
+	if es.CreatedAt.IsZero() {
+		// omitzero is ignored before Go 1.24, so shadow the field with
+		// an omitted one to keep legacy values free of created_at.
+		type encryptedString EncryptedString
+
+		out := must(json.Marshal(struct {
+			*encryptedString
+			CreatedAt *time.Time `json:"created_at,omitempty"`
+		}{encryptedString: (*encryptedString)(es)}))
+
+		return string(out)
+	}
+
 	out := must(json.Marshal(es))
 
 	return string(out)
 }
 
-func deriveSymmetricKey(id, keyID, keyBase64URL string) ([]byte, error) {
+func (es *EncryptedString) deriveSymmetricKey(id, keyBase64URL string) ([]byte, error) {
 	hkdfKey, err := base64.RawURLEncoding.DecodeString(keyBase64URL)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(hkdfKey) != 256/8 {
-		return nil, fmt.Errorf("crypto: key with ID %q is not 256 bits", keyID)
+		return nil, fmt.Errorf("crypto: key with ID %q is not 256 bits", es.qualifiedKeyID())
 	}
 
 	// Since we use AES-GCM here, the same symmetric key *must not be used
@@ -121,36 +660,155 @@ func deriveSymmetricKey(id, keyID, keyBase64URL string) ([]byte, error) {
 	// has the added benefit that the encrypted string is bound to that
 	// specific object, and can't accidentally be "moved" to other objects
 	// without changing their ID to the original one.
+	//
+	// When a counter is set it's appended to the ID, so every version of
+	// the object gets its own key. Values without a counter keep the
+	// original derivation. The same goes for the purpose, which is
+	// appended after a zero byte.
+
+	info := []byte(id)
+	if es.Counter != 0 {
+		info = binary.BigEndian.AppendUint64(info, es.Counter)
+	}
 
-	keyReader := hkdf.New(sha256.New, hkdfKey, nil, []byte(id))
-	key := make([]byte, 256/8)
+	if es.Purpose != "" {
+		info = append(info, 0)
+		info = append(info, es.Purpose...)
+	}
+
+	cipherAlgorithm, hkdfHash := splitAlgorithm(es.Algorithm)
+
+	keySize := 256 / 8
+	if cipherAlgorithm == AlgorithmAESCBCHMACSHA256 {
+		keySize = cbcHMACKeySize
+	}
+
+	keyReader := hkdf.New(hkdfHashes[hkdfHash], hkdfKey, es.Salt, info)
+	key := make([]byte, keySize)
 
 	must(io.ReadFull(keyReader, key))
 
 	return key, nil
 }
 
-func NewEncryptedString(id string, data []byte, keyID string, keyBase64URL string) (*EncryptedString, error) {
-	key, err := deriveSymmetricKey(id, keyID, keyBase64URL)
+// NewEncryptedString encrypts data belonging to the object with the given
+// ID using a key derived from the 256 bit base64url encoded key. Use the
+// key's thumbprint, as returned by KeyThumbprint, as its key ID unless an
+// existing naming scheme is already in place.
+func NewEncryptedString(id string, data []byte, keyID string, keyBase64URL string, opts ...EncryptedStringOption) (*EncryptedString, error) {
+	options := newEncryptedStringOptions(opts)
+
+	es := EncryptedString{
+		Version:      encryptedStringVersionNilSalt,
+		KeyID:        keyID,
+		KeyNamespace: options.keyNamespace,
+		Algorithm:    options.algorithmName(),
+		Counter:      options.counter,
+		Compression:  string(options.compression),
+		Purpose:      options.purpose,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if err := options.checkFIPS(es.Algorithm); err != nil {
+		return nil, err
+	}
+
+	data, err := compress(options.compression, data)
 	if err != nil {
 		return nil, err
 	}
 
-	block := must(aes.NewCipher(key))
-	cipher := must(cipher.NewGCM(block))
+	if options.hkdfSalt {
+		es.Version = encryptedStringVersionSalted
+		es.Salt = make([]byte, 32)
+		must(io.ReadFull(rand.Reader, es.Salt))
+	}
 
-	es := EncryptedString{
-		KeyID:     keyID,
-		Algorithm: "aes-gcm-hkdf",
-		Nonce:     make([]byte, 12),
+	key, err := es.deriveSymmetricKey(id, keyBase64URL)
+	if err != nil {
+		return nil, err
 	}
 
+	cipher, err := newAEAD(es.Algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	es.Nonce = make([]byte, cipher.NonceSize())
 	must(io.ReadFull(rand.Reader, es.Nonce))
-	es.Data = cipher.Seal(nil, es.Nonce, data, nil) // #nosec G407
+	es.setSealed(cipher.Seal(nil, es.Nonce, data, es.additionalData())) // #nosec G407
 
 	return &es, nil
 }
 
+// SecureToken creates a new random token of 16 bytes, encoded as a 22
+// character base64url string.
+func SecureToken() string {
+	return SecureTokenN(16)
+}
+
+// SecureTokenN creates a new random token of byteLen bytes, encoded as
+// base64url without padding. It panics if byteLen is less than 8, as such
+// tokens are too easy to guess.
+func SecureTokenN(byteLen int) string {
+	if byteLen < 8 {
+		panic(fmt.Sprintf("crypto: secure token must be at least 8 bytes, got %d", byteLen))
+	}
+
+	b := make([]byte, byteLen)
+	must(io.ReadFull(rand.Reader, b))
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Alphabets for SecureTokenAlpha.
+const (
+	// AlphabetBase58 is the Bitcoin base58 alphabet, which leaves out 0, O,
+	// I and l.
+	AlphabetBase58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// AlphabetAlphanumericClear holds the upper case letters and digits
+	// that can't be confused with each other, leaving out 0, O, 1 and I.
+	AlphabetAlphanumericClear = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+	// AlphabetDecimal holds the decimal digits.
+	AlphabetDecimal = "0123456789"
+)
+
+// SecureTokenAlpha creates a new random token with at least byteLen bytes
+// of entropy, using only characters from alphabet. It's meant for codes
+// read or typed by people, such as backup codes. Random bytes outside the
+// largest multiple of the alphabet size are rejected, so every character
+// is equally likely. It panics if byteLen is less than 8 or the alphabet
+// has fewer than 2 or more than 256 characters.
+func SecureTokenAlpha(byteLen int, alphabet string) string {
+	if byteLen < 8 {
+		panic(fmt.Sprintf("crypto: secure token must be at least 8 bytes, got %d", byteLen))
+	}
+
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		panic(fmt.Sprintf("crypto: secure token alphabet must have between 2 and 256 characters, got %d", len(alphabet)))
+	}
+
+	length := int(math.Ceil(float64(byteLen*8) / math.Log2(float64(len(alphabet)))))
+	limit := 256 - 256%len(alphabet)
+
+	token := make([]byte, 0, length)
+	buf := make([]byte, length)
+
+	for len(token) < length {
+		must(io.ReadFull(rand.Reader, buf))
+
+		for _, b := range buf {
+			if int(b) < limit && len(token) < length {
+				token = append(token, alphabet[int(b)%len(alphabet)])
+			}
+		}
+	}
+
+	return string(token)
+}
+
 // SecureAlphanumeric generates a secure random alphanumeric string using standard library
 func SecureAlphanumeric(length int) string {
 	if length < 8 {
@@ -1,10 +1,14 @@
 package crypto
 
 import (
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEncryptedStringPositive(t *testing.T) {
@@ -33,6 +37,200 @@ func TestEncryptedStringPositive(t *testing.T) {
 	assert.Equal(t, []byte("data"), decrypted)
 }
 
+func TestEncryptedStringVersion(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	// salted values are only written when asked for, so releases that
+	// don't read version 2 can still decrypt new values
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"])
+	require.NoError(t, err)
+	assert.Equal(t, 1, es.Version)
+	assert.Contains(t, es.String(), `"version":1`)
+	assert.Nil(t, es.Salt)
+
+	es, err = NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithHKDFSalt())
+	require.NoError(t, err)
+	assert.Equal(t, EncryptedStringVersion, es.Version)
+	assert.Contains(t, es.String(), `"version":2`)
+
+	// values written before versioning have no version field or salt
+	legacyID := "4f8a0f5e-0d7a-4a46-9d3b-2b2f4c1a6e10"
+	legacy := ParseEncryptedString(`{"key_id":"key-id","alg":"aes-gcm-hkdf","data":"ZgijZYbAqFsMWc2+XDUn6+3Je7s=","nonce":"vploBjFT7dCxxsdX"}`)
+	require.NotNil(t, legacy)
+	assert.Equal(t, 0, legacy.Version)
+	assert.Nil(t, legacy.Salt)
+	assert.True(t, legacy.IsValid())
+
+	decrypted, err := legacy.Decrypt(legacyID, keys)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	// versions from the future are rejected
+	legacy.Version = EncryptedStringVersion + 1
+	assert.False(t, legacy.IsValid())
+
+	_, err = legacy.Decrypt(legacyID, keys)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestEncryptedStringSalt(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithHKDFSalt())
+	require.NoError(t, err)
+	assert.Len(t, es.Salt, 32)
+
+	other, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithHKDFSalt())
+	require.NoError(t, err)
+	assert.NotEqual(t, es.Salt, other.Salt)
+
+	dec := ParseEncryptedString(es.String())
+	require.NotNil(t, dec)
+	assert.Equal(t, es.Salt, dec.Salt)
+
+	decrypted, err := dec.Decrypt(id, keys)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	// the salt is bound to the ciphertext through the derived key
+	dec.Salt = other.Salt
+	_, err = dec.Decrypt(id, keys)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	dec.Salt = nil
+	_, err = dec.Decrypt(id, keys)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestEncryptedStringCounter(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithCounter(7))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), es.Counter)
+
+	dec := ParseEncryptedString(es.String())
+	assert.NotNil(t, dec)
+	assert.Equal(t, uint64(7), dec.Counter)
+
+	decrypted, err := dec.Decrypt(id, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	// each counter value derives a different key
+	dec.Counter = 8
+	_, err = dec.Decrypt(id, keys)
+	assert.Error(t, err)
+
+	dec.Counter = 0
+	_, err = dec.Decrypt(id, keys)
+	assert.Error(t, err)
+
+	// no counter is omitted from the JSON
+	es, err = NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"])
+	assert.NoError(t, err)
+	assert.NotContains(t, es.String(), "counter")
+}
+
+func TestEncryptedStringXChaCha20Poly1305(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithAlgorithm(AlgorithmXChaCha20Poly1305HKDF))
+	assert.NoError(t, err)
+
+	assert.Equal(t, es.Algorithm, "xchacha20poly1305-hkdf")
+	assert.Len(t, es.Data, 20)
+	assert.Len(t, es.Nonce, 24)
+
+	dec := ParseEncryptedString(es.String())
+
+	assert.NotNil(t, dec)
+	assert.Equal(t, dec.Algorithm, "xchacha20poly1305-hkdf")
+
+	decrypted, err := dec.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+}
+
+func TestEncryptedStringChaCha20Poly1305(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithAlgorithm(AlgorithmChaCha20Poly1305HKDF))
+	assert.NoError(t, err)
+
+	assert.Equal(t, es.Algorithm, "chacha20-poly1305-hkdf")
+	assert.Len(t, es.Data, 20)
+	assert.Len(t, es.Nonce, 12)
+
+	dec := ParseEncryptedString(es.String())
+
+	assert.NotNil(t, dec)
+	assert.Equal(t, dec.Algorithm, "chacha20-poly1305-hkdf")
+
+	decrypted, err := dec.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	assert.True(t, dec.ShouldReEncrypt("key-id"))
+	assert.False(t, dec.ShouldReEncrypt("key-id", WithAlgorithm(AlgorithmChaCha20Poly1305HKDF)))
+	assert.True(t, dec.ShouldReEncrypt("new-key-id", WithAlgorithm(AlgorithmChaCha20Poly1305HKDF)))
+	assert.False(t, dec.ShouldRotate(time.Hour, "key-id", WithAlgorithm(AlgorithmChaCha20Poly1305HKDF)))
+}
+
+func TestEncryptedStringUnsupportedAlgorithm(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	_, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithAlgorithm("different"))
+	assert.Error(t, err)
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	assert.NoError(t, err)
+
+	es.Algorithm = "different"
+
+	_, err = es.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+	assert.Error(t, err)
+}
+
+func TestEncryptedStringInvalidNonceLength(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	assert.NoError(t, err)
+	assert.True(t, es.IsValid())
+
+	es.Nonce = make([]byte, 13)
+	assert.False(t, es.IsValid())
+
+	_, err = es.Decrypt(id, map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+	assert.ErrorIs(t, err, ErrInvalidNonceLength)
+
+	// AES-GCM sized nonce on an XChaCha20-Poly1305 value
+	es.Algorithm = AlgorithmXChaCha20Poly1305HKDF
+	es.Nonce = make([]byte, 12)
+	assert.False(t, es.IsValid())
+	assert.Nil(t, ParseEncryptedString(es.String()))
+}
+
 func TestParseEncryptedStringNegative(t *testing.T) {
 	negativeExamples := []string{
 		"not-an-encrypted-string",
@@ -47,6 +245,7 @@ func TestParseEncryptedStringNegative(t *testing.T) {
 		`{}`,
 		`{"key_id":"key_id"}`,
 		`{"key_id":"key_id","alg":"different","data":"AQAB=","nonce":"AQAB="}`,
+		`{"key_id":"key_id","alg":"different","data":"AQAB","nonce":"AQAB"}`,
 	}
 
 	for _, example := range negativeExamples {
@@ -74,7 +273,7 @@ func TestEncryptedStringDecryptNegative(t *testing.T) {
 	_, err = dec.Decrypt(id, map[string]string{
 		// empty map
 	})
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
 
 	// short key
 	_, err = dec.Decrypt(id, map[string]string{
@@ -92,7 +291,7 @@ func TestEncryptedStringDecryptNegative(t *testing.T) {
 	_, err = dec.Decrypt(id, map[string]string{
 		"key-id": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
 	})
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
 
 	// bad tag for AEAD failure
 	dec.Data[len(dec.Data)-1] += 1
@@ -100,9 +299,326 @@ func TestEncryptedStringDecryptNegative(t *testing.T) {
 	_, err = dec.Decrypt(id, map[string]string{
 		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
 	})
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestEncryptedStringKeyNamespace(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "main-key", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", WithKeyNamespace("payments"))
+	assert.NoError(t, err)
+	assert.Equal(t, "main-key", es.KeyID)
+	assert.Equal(t, "payments", es.KeyNamespace)
+
+	dec := ParseEncryptedString(es.String())
+	assert.NotNil(t, dec)
+	assert.Equal(t, "payments", dec.KeyNamespace)
+
+	decrypted, err := dec.Decrypt(id, map[string]string{
+		"payments/main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}, WithKeyNamespace("payments"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	// key not present under the namespaced key ID
+	_, err = dec.Decrypt(id, map[string]string{
+		"main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}, WithKeyNamespace("payments"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// decrypting in another namespace
+	_, err = dec.Decrypt(id, map[string]string{
+		"auth/main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}, WithKeyNamespace("auth"))
+	assert.Error(t, err)
+
+	// decrypting without a namespace
+	_, err = dec.Decrypt(id, map[string]string{
+		"main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
+	assert.Error(t, err)
+
+	// stripping the namespace from the encrypted string
+	dec.KeyNamespace = ""
+	_, err = dec.Decrypt(id, map[string]string{
+		"main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	})
 	assert.Error(t, err)
 }
 
+func TestEncryptedStringPurpose(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"main-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	es, err := NewEncryptedString(id, []byte("data"), "main-key", keys["main-key"], WithPurpose("refresh_token"))
+	require.NoError(t, err)
+	assert.Equal(t, "refresh_token", es.Purpose)
+
+	dec := ParseEncryptedString(es.String())
+	require.NotNil(t, dec)
+	assert.Equal(t, "refresh_token", dec.Purpose)
+
+	decrypted, err := dec.Decrypt(id, keys, WithPurpose("refresh_token"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	// decrypting for another purpose
+	_, err = dec.Decrypt(id, keys, WithPurpose("mfa_backup"))
+	assert.Error(t, err)
+
+	// decrypting without a purpose
+	_, err = dec.Decrypt(id, keys)
+	assert.Error(t, err)
+
+	// changing the purpose in the encrypted string
+	dec.Purpose = "mfa_backup"
+	_, err = dec.Decrypt(id, keys, WithPurpose("mfa_backup"))
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// stripping the purpose from the encrypted string
+	dec.Purpose = ""
+	_, err = dec.Decrypt(id, keys)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
 func TestSecureToken(t *testing.T) {
 	assert.Equal(t, len(SecureAlphanumeric(22)), 22)
+
+	assert.Len(t, SecureToken(), 22)
+	assert.NotEqual(t, SecureToken(), SecureToken())
+
+	assert.Len(t, SecureTokenN(8), 11)
+	assert.Len(t, SecureTokenN(32), 43)
+
+	assert.Panics(t, func() {
+		SecureTokenN(7)
+	})
+}
+
+func TestGenerateOtpChannels(t *testing.T) {
+	otps := GenerateOtpChannels(6, 2)
+	require.Len(t, otps, 2)
+
+	for _, otp := range otps {
+		assert.Len(t, otp, 6)
+	}
+
+	assert.Empty(t, GenerateOtpChannels(6, 0))
+
+	hashes := GenerateSingleOtpMultipleTokenHashes([]string{"user@example.com", "12345678"}, "123456")
+	assert.Equal(t, []string{
+		GenerateTokenHash("user@example.com", "123456"),
+		GenerateTokenHash("12345678", "123456"),
+	}, hashes)
+}
+
+func TestGenerateTokenHashAlgo(t *testing.T) {
+	cases := map[string]string{
+		"sha224": "b507a4ef007e1379d644aa6fe395cf98f1054ac96b63a052dca9a26a",
+		"sha256": "8c30d00f556574eb16bbe30345c9183f92a3e13a70b515ddbc171f15cad31f6e",
+		"sha512": "938a906abe3a359eebeef0ca9472cf0821ada3c7875063b835e7029b94d5bd9731989533e9c8737845e2e43d55c154468299f20227c75ad4de070d92d3f7eb79",
+	}
+
+	for algorithm, expected := range cases {
+		hash, err := GenerateTokenHashAlgo("user@example.com", "123456", algorithm)
+		require.NoError(t, err)
+		assert.Equal(t, expected, hash, algorithm)
+	}
+
+	assert.Equal(t, cases["sha224"], GenerateTokenHash("user@example.com", "123456"))
+
+	_, err := GenerateTokenHashAlgo("user@example.com", "123456", "md5")
+	assert.ErrorIs(t, err, ErrUnknownHashAlgorithm)
+}
+
+func TestSecureTokenAlpha(t *testing.T) {
+	assert.Len(t, SecureTokenAlpha(16, AlphabetBase58), 22)
+	assert.Len(t, SecureTokenAlpha(16, AlphabetAlphanumericClear), 26)
+	assert.Len(t, SecureTokenAlpha(16, AlphabetDecimal), 39)
+	assert.NotEqual(t, SecureTokenAlpha(16, AlphabetBase58), SecureTokenAlpha(16, AlphabetBase58))
+
+	assert.Panics(t, func() {
+		SecureTokenAlpha(7, AlphabetBase58)
+	})
+
+	assert.Panics(t, func() {
+		SecureTokenAlpha(16, "a")
+	})
+
+	assert.Panics(t, func() {
+		SecureTokenAlpha(16, strings.Repeat("a", 257))
+	})
+
+	// Every character of the alphabet must be equally likely. The critical
+	// values use the Wilson-Hilferty approximation of the chi-squared
+	// distribution at p = 10^-6, so the test is practically never flaky.
+	for _, alphabet := range []string{AlphabetBase58, AlphabetAlphanumericClear, AlphabetDecimal, "abc"} {
+		counts := make(map[rune]int)
+		total := 0
+
+		for total < 200_000 {
+			for _, c := range SecureTokenAlpha(64, alphabet) {
+				counts[c] += 1
+				total += 1
+			}
+		}
+
+		require.Len(t, counts, len(alphabet), alphabet)
+
+		expected := float64(total) / float64(len(alphabet))
+		chiSquared := 0.0
+
+		for _, count := range counts {
+			chiSquared += (float64(count) - expected) * (float64(count) - expected) / expected
+		}
+
+		df := float64(len(alphabet) - 1)
+		critical := df * math.Pow(1-2/(9*df)+4.753*math.Sqrt(2/(9*df)), 3)
+
+		assert.Less(t, chiSquared, critical, alphabet)
+	}
+}
+
+func TestEncryptedStringAge(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	assert.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now(), es.CreatedAt, time.Minute)
+
+	dec := ParseEncryptedString(es.String())
+	assert.NotNil(t, dec)
+	assert.True(t, es.CreatedAt.Equal(dec.CreatedAt))
+
+	assert.Less(t, dec.Age(), time.Minute)
+	assert.False(t, dec.IsOlderThan(time.Hour))
+	assert.False(t, dec.ShouldRotate(time.Hour, "key-id"))
+	assert.True(t, dec.ShouldRotate(time.Hour, "new-key-id"))
+
+	dec.CreatedAt = time.Now().Add(-2 * time.Hour)
+	assert.True(t, dec.IsOlderThan(time.Hour))
+	assert.True(t, dec.ShouldRotate(time.Hour, "key-id"))
+
+	// values encrypted before CreatedAt existed are always due for rotation
+	legacyJSON := `{"key_id":"key-id","alg":"aes-gcm-hkdf","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`
+	legacy := ParseEncryptedString(legacyJSON)
+	assert.NotNil(t, legacy)
+	assert.True(t, legacy.CreatedAt.IsZero())
+	assert.True(t, legacy.IsOlderThan(100*365*24*time.Hour))
+	assert.True(t, legacy.ShouldRotate(time.Hour, "key-id"))
+
+	// legacy values round-trip without gaining a created_at field
+	assert.JSONEq(t, legacyJSON, legacy.String())
+	assert.NotContains(t, legacy.String(), "created_at")
+	assert.Contains(t, es.String(), `"created_at":`)
+}
+
+func TestEncryptedStringHKDFHash(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	examples := []struct {
+		opts      []EncryptedStringOption
+		algorithm string
+	}{
+		{nil, "aes-gcm-hkdf"},
+		{[]EncryptedStringOption{WithHKDFHash(HKDFSHA256)}, "aes-gcm-hkdf"},
+		{[]EncryptedStringOption{WithHKDFHash(HKDFSHA384)}, "aes-gcm-hkdf-sha384"},
+		{[]EncryptedStringOption{WithHKDFHash(HKDFSHA512)}, "aes-gcm-hkdf-sha512"},
+		{[]EncryptedStringOption{WithAlgorithm(AlgorithmXChaCha20Poly1305HKDF), WithHKDFHash(HKDFSHA384)}, "xchacha20poly1305-hkdf-sha384"},
+	}
+
+	for _, example := range examples {
+		es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], example.opts...)
+		require.NoError(t, err)
+		assert.Equal(t, example.algorithm, es.Algorithm)
+		assert.False(t, es.ShouldReEncrypt("key-id", example.opts...))
+
+		dec := ParseEncryptedString(es.String())
+		require.NotNil(t, dec)
+
+		decrypted, err := dec.Decrypt(id, keys)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), decrypted)
+	}
+
+	// the hash is bound to the algorithm name, so changing it breaks decryption
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithHKDFHash(HKDFSHA384))
+	require.NoError(t, err)
+	assert.True(t, es.ShouldReEncrypt("key-id"))
+
+	es.Algorithm = AlgorithmAESGCMHKDF + "-sha512"
+	_, err = es.Decrypt(id, keys)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithHKDFHash("md5"))
+	assert.Error(t, err)
+
+	assert.Nil(t, ParseEncryptedString(`{"key_id":"key-id","alg":"aes-gcm-hkdf-md5","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`))
+	assert.NotNil(t, ParseEncryptedString(`{"key_id":"key-id","alg":"aes-gcm-hkdf-sha384","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`))
+}
+
+func TestEncryptedStringFIPSMode(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+	keys := map[string]string{
+		"key-id": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	for _, opts := range [][]EncryptedStringOption{
+		{WithFIPSMode()},
+		{WithFIPSMode(), WithHKDFHash(HKDFSHA256)},
+		{WithFIPSMode(), WithAlgorithm(AlgorithmChaCha20Poly1305HKDF), WithHKDFHash(HKDFSHA384)},
+	} {
+		_, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], opts...)
+		assert.ErrorIs(t, err, ErrAlgorithmNotFIPSCompliant)
+	}
+
+	es, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"], WithFIPSMode(), WithHKDFHash(HKDFSHA384))
+	require.NoError(t, err)
+
+	decrypted, err := es.Decrypt(id, keys, WithFIPSMode())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	legacy, err := NewEncryptedString(id, []byte("data"), "key-id", keys["key-id"])
+	require.NoError(t, err)
+
+	_, err = legacy.Decrypt(id, keys, WithFIPSMode())
+	assert.ErrorIs(t, err, ErrAlgorithmNotFIPSCompliant)
+}
+
+func TestParseEncryptedStringE(t *testing.T) {
+	es, err := NewEncryptedString(uuid.Must(uuid.NewV4()).String(), []byte("data"), "key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	require.NoError(t, err)
+
+	parsed, err := ParseEncryptedStringE(es.String())
+	require.NoError(t, err)
+	assert.Equal(t, es.Data, parsed.Data)
+
+	examples := []struct {
+		str string
+		err error
+	}{
+		{"", ErrNotEncrypted},
+		{"plaintext", ErrNotEncrypted},
+		{"{", ErrMalformedJSON},
+		{`{"key_id":1}`, ErrMalformedJSON},
+		{`{"key_id":"key_id","alg":"different","data":"AQAB","nonce":"AQAB"}`, ErrUnsupportedAlgorithm},
+		{`{"key_id":"key_id","alg":"aes-gcm-hkdf","data":"AQAB","nonce":"AQAB"}`, ErrInvalidEncryptedString},
+		{`{"alg":"aes-gcm-hkdf","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`, ErrInvalidEncryptedString},
+		{`{"version":3,"key_id":"key_id","alg":"aes-gcm-hkdf","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`, ErrUnsupportedVersion},
+		{`{"version":-1,"key_id":"key_id","alg":"aes-gcm-hkdf","data":"AQAB","nonce":"AAAAAAAAAAAAAAAA"}`, ErrUnsupportedVersion},
+	}
+
+	for _, example := range examples {
+		parsed, err := ParseEncryptedStringE(example.str)
+		assert.Nil(t, parsed, example.str)
+		assert.ErrorIs(t, err, example.err, example.str)
+		assert.Nil(t, ParseEncryptedString(example.str), example.str)
+	}
 }
@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadEncryptionKeysFromEnv reads the encryption keys from the environment
+// variables named {prefix}_KEY_{ID}, such as GOTRUE_ENCRYPTION_KEY_main for
+// the key with ID "main". The returned map can be used as the decryption
+// keys for Decrypt. Each key must be a base64url encoded 256 bit key.
+func LoadEncryptionKeysFromEnv(prefix string) (map[string]string, error) {
+	namePrefix := prefix + "_KEY_"
+	keys := make(map[string]string)
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+
+		keyID, found := strings.CutPrefix(name, namePrefix)
+		if !found {
+			continue
+		}
+
+		if keyID == "" {
+			return nil, fmt.Errorf("crypto: environment variable %q has no key ID", name)
+		}
+
+		key, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key in environment variable %q is not base64url encoded: %w", name, err)
+		}
+
+		if len(key) != 256/8 {
+			return nil, fmt.Errorf("crypto: key in environment variable %q is not 256 bits", name)
+		}
+
+		keys[keyID] = value
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,49 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEncryptionKeysFromEnv(t *testing.T) {
+	t.Setenv("TEST_ENCRYPTION_KEY_main", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	t.Setenv("TEST_ENCRYPTION_KEY_old-key", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	t.Setenv("TEST_ENCRYPTION_OTHER", "not-a-key")
+
+	keys, err := LoadEncryptionKeysFromEnv("TEST_ENCRYPTION")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"main":    "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+		"old-key": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+	}, keys)
+
+	es, err := NewEncryptedString("id", []byte("data"), "main", keys["main"])
+	require.NoError(t, err)
+
+	decrypted, err := es.Decrypt("id", keys)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	keys, err = LoadEncryptionKeysFromEnv("TEST_MISSING")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestLoadEncryptionKeysFromEnvNegative(t *testing.T) {
+	examples := map[string]string{
+		"TEST_NEGATIVE_KEY_":      "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+		"TEST_NEGATIVE_KEY_bad":   "!!!",
+		"TEST_NEGATIVE_KEY_short": "AAAA",
+	}
+
+	for name, value := range examples {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+
+			_, err := LoadEncryptionKeysFromEnv("TEST_NEGATIVE")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// EnvelopeKeyProvider issues and unwraps data encryption keys (DEKs), usually
+// backed by a KMS holding the key encryption key. DEKs must be 256 bits.
+type EnvelopeKeyProvider interface {
+	// GenerateDEK returns a new DEK in plaintext and wrapped form, along
+	// with the ID of the key that wrapped it.
+	GenerateDEK(ctx context.Context) (plaintextKey []byte, encryptedKey []byte, keyID string, err error)
+
+	// DecryptDEK unwraps a DEK previously returned by GenerateDEK.
+	DecryptDEK(ctx context.Context, keyID string, encryptedKey []byte) (plaintextKey []byte, err error)
+}
+
+// NewEncryptedStringWithEnvelope encrypts data with a fresh DEK from the
+// provider. The wrapped DEK is returned alongside the encrypted string and
+// must be stored with it, as it's needed by DecryptWithEnvelope.
+func NewEncryptedStringWithEnvelope(ctx context.Context, id string, data []byte, provider EnvelopeKeyProvider, opts ...EncryptedStringOption) (*EncryptedString, []byte, error) {
+	plaintextKey, encryptedKey, keyID, err := provider.GenerateDEK(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	es, err := NewEncryptedString(id, data, keyID, base64.RawURLEncoding.EncodeToString(plaintextKey), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return es, encryptedKey, nil
+}
+
+// DecryptWithEnvelope unwraps the DEK with the provider and uses it to
+// decrypt the encrypted string.
+func (es *EncryptedString) DecryptWithEnvelope(ctx context.Context, id string, encryptedKey []byte, provider EnvelopeKeyProvider, opts ...EncryptedStringOption) ([]byte, error) {
+	plaintextKey, err := provider.DecryptDEK(ctx, es.KeyID, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return es.Decrypt(id, map[string]string{
+		es.qualifiedKeyID(): base64.RawURLEncoding.EncodeToString(plaintextKey),
+	}, opts...)
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnvelopeKeyProvider "wraps" DEKs by XOR-ing them with a master key.
+type testEnvelopeKeyProvider struct {
+	masterKey []byte
+	dekSize   int
+	err       error
+}
+
+func (p *testEnvelopeKeyProvider) wrap(key []byte) []byte {
+	wrapped := make([]byte, len(key))
+
+	for i := range key {
+		wrapped[i] = key[i] ^ p.masterKey[i%len(p.masterKey)]
+	}
+
+	return wrapped
+}
+
+func (p *testEnvelopeKeyProvider) GenerateDEK(ctx context.Context) ([]byte, []byte, string, error) {
+	if p.err != nil {
+		return nil, nil, "", p.err
+	}
+
+	key := make([]byte, p.dekSize)
+	must(rand.Read(key))
+
+	return key, p.wrap(key), "kms-key", nil
+}
+
+func (p *testEnvelopeKeyProvider) DecryptDEK(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error) {
+	if keyID != "kms-key" {
+		return nil, errors.New("unknown key")
+	}
+
+	return p.wrap(encryptedKey), nil
+}
+
+func TestEncryptedStringEnvelope(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.Must(uuid.NewV4()).String()
+
+	provider := &testEnvelopeKeyProvider{
+		masterKey: []byte("master-key"),
+		dekSize:   32,
+	}
+
+	es, encryptedKey, err := NewEncryptedStringWithEnvelope(ctx, id, []byte("data"), provider, WithKeyNamespace("payments"))
+	require.NoError(t, err)
+	require.Equal(t, "kms-key", es.KeyID)
+	require.Len(t, encryptedKey, 32)
+
+	decrypted, err := es.DecryptWithEnvelope(ctx, id, encryptedKey, provider, WithKeyNamespace("payments"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), decrypted)
+
+	// wrong wrapped DEK
+	encryptedKey[0] ^= 1
+	_, err = es.DecryptWithEnvelope(ctx, id, encryptedKey, provider, WithKeyNamespace("payments"))
+	require.Error(t, err)
+
+	// provider unable to unwrap
+	es.KeyID = "other-key"
+	_, err = es.DecryptWithEnvelope(ctx, id, encryptedKey, provider, WithKeyNamespace("payments"))
+	require.Error(t, err)
+
+	// DEK of the wrong size
+	provider.dekSize = 16
+	_, _, err = NewEncryptedStringWithEnvelope(ctx, id, []byte("data"), provider)
+	require.Error(t, err)
+
+	// provider unable to generate
+	provider.err = errors.New("kms unavailable")
+	_, _, err = NewEncryptedStringWithEnvelope(ctx, id, []byte("data"), provider)
+	require.Error(t, err)
+}
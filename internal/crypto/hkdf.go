@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// HKDFExpandMulti derives numKeys independent keys of keyLen bytes each from
+// the secret with a single HKDF-SHA256 run, for callers that need several
+// keys (such as an encryption and a MAC key) from the same root material.
+func HKDFExpandMulti(secret []byte, info string, numKeys int, keyLen int) ([][]byte, error) {
+	if numKeys < 1 || keyLen < 1 {
+		return nil, errors.New("crypto: HKDF needs at least one key of at least one byte")
+	}
+
+	if numKeys*keyLen > 255*sha256.Size {
+		return nil, errors.New("crypto: HKDF-SHA256 can't derive more than 8160 bytes")
+	}
+
+	output := make([]byte, numKeys*keyLen)
+	must(io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(info)), output))
+
+	keys := make([][]byte, numKeys)
+	for i := range keys {
+		keys[i] = output[i*keyLen : (i+1)*keyLen : (i+1)*keyLen]
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
+)
+
+func TestHKDFExpandMulti(t *testing.T) {
+	secret := []byte("root secret material")
+
+	keys, err := HKDFExpandMulti(secret, "purpose", 3, 32)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	for i, key := range keys {
+		require.Len(t, key, 32)
+
+		for j := i + 1; j < len(keys); j += 1 {
+			require.NotEqual(t, key, keys[j])
+		}
+	}
+
+	// keys are the consecutive blocks of a single HKDF output
+	expected := make([]byte, 96)
+	_, err = io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte("purpose")), expected)
+	require.NoError(t, err)
+
+	require.Equal(t, hex.EncodeToString(expected[:32]), hex.EncodeToString(keys[0]))
+	require.Equal(t, hex.EncodeToString(expected[32:64]), hex.EncodeToString(keys[1]))
+	require.Equal(t, hex.EncodeToString(expected[64:]), hex.EncodeToString(keys[2]))
+
+	// deterministic
+	again, err := HKDFExpandMulti(secret, "purpose", 3, 32)
+	require.NoError(t, err)
+	require.Equal(t, keys, again)
+
+	// bound to info
+	other, err := HKDFExpandMulti(secret, "other purpose", 3, 32)
+	require.NoError(t, err)
+	require.NotEqual(t, keys[0], other[0])
+
+	_, err = HKDFExpandMulti(secret, "purpose", 0, 32)
+	require.Error(t, err)
+
+	_, err = HKDFExpandMulti(secret, "purpose", 1, 0)
+	require.Error(t, err)
+
+	_, err = HKDFExpandMulti(secret, "purpose", 256, 32)
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignHMAC computes the HMAC-SHA256 of the message, for callers that need
+// integrity but not confidentiality, such as signing webhooks or cookies.
+func SignHMAC(secret, message []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	must(mac.Write(message))
+
+	return mac.Sum(nil)
+}
+
+// VerifyHMAC checks in constant time that mac is the HMAC-SHA256 of the
+// message.
+func VerifyHMAC(secret, message, mac []byte) bool {
+	return hmac.Equal(SignHMAC(secret, message), mac)
+}
+
+// SignHMACHex is like SignHMAC but returns the MAC hex encoded.
+func SignHMACHex(secret, message []byte) string {
+	return hex.EncodeToString(SignHMAC(secret, message))
+}
+
+// VerifyHMACHex is like VerifyHMAC but takes a hex encoded MAC.
+func VerifyHMACHex(secret, message []byte, macHex string) bool {
+	mac, err := hex.DecodeString(macHex)
+	if err != nil {
+		return false
+	}
+
+	return VerifyHMAC(secret, message, mac)
+}
@@ -0,0 +1,26 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHMAC(t *testing.T) {
+	// test case 2 from RFC 4231
+	secret := []byte("Jefe")
+	message := []byte("what do ya want for nothing?")
+	expected := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+
+	assert.Equal(t, expected, SignHMACHex(secret, message))
+	assert.Len(t, SignHMAC(secret, message), 32)
+
+	assert.True(t, VerifyHMAC(secret, message, SignHMAC(secret, message)))
+	assert.False(t, VerifyHMAC([]byte("other"), message, SignHMAC(secret, message)))
+	assert.False(t, VerifyHMAC(secret, []byte("other"), SignHMAC(secret, message)))
+	assert.False(t, VerifyHMAC(secret, message, nil))
+
+	assert.True(t, VerifyHMACHex(secret, message, expected))
+	assert.False(t, VerifyHMACHex(secret, message, expected[:62]+"00"))
+	assert.False(t, VerifyHMACHex(secret, message, "not hex"))
+}
@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// KeyThumbprint computes the JWK Thumbprint (RFC 7638) of a 256 bit
+// encryption key, treating it as a JWK with key type "oct". The thumbprint
+// is a standard, deterministic identifier for the key and is recommended as
+// the key ID of encrypted strings.
+func KeyThumbprint(keyBase64URL string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(keyBase64URL)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) != 256/8 {
+		return "", errors.New("crypto: key is not 256 bits")
+	}
+
+	// The required members of an "oct" JWK in lexicographic order, without
+	// whitespace, as defined in RFC 7638 section 3.2.
+	canonical := `{"k":"` + base64.RawURLEncoding.EncodeToString(key) + `","kty":"oct"}`
+	thumbprint := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:]), nil
+}
+
+// GenerateKeyWithThumbprint generates a new random 256 bit encryption key
+// and returns it along with its thumbprint, to be used as its key ID.
+func GenerateKeyWithThumbprint() (keyBase64URL, thumbprint string, err error) {
+	key := make([]byte, 256/8)
+	must(io.ReadFull(rand.Reader, key))
+
+	keyBase64URL = base64.RawURLEncoding.EncodeToString(key)
+
+	return keyBase64URL, must(KeyThumbprint(keyBase64URL)), nil
+}
+
+// octJWK is a JSON Web Key with key type "oct" (RFC 7518 section 6.4).
+type octJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	K   string `json:"k"`
+}
+
+type octJWKS struct {
+	Keys []octJWK `json:"keys"`
+}
+
+func newOctJWK(keyID, keyBase64URL string) (octJWK, error) {
+	key, err := base64.RawURLEncoding.DecodeString(keyBase64URL)
+	if err != nil {
+		return octJWK{}, err
+	}
+
+	if len(key) != 256/8 {
+		return octJWK{}, fmt.Errorf("crypto: key with ID %q is not 256 bits", keyID)
+	}
+
+	return octJWK{
+		Kty: "oct",
+		Kid: keyID,
+		K:   keyBase64URL,
+	}, nil
+}
+
+// key returns the key ID and key of the JWK. JWKs without a "kid" get their
+// thumbprint as key ID.
+func (j octJWK) key() (keyID, keyBase64URL string, err error) {
+	if j.Kty != "oct" {
+		return "", "", fmt.Errorf("crypto: JWK has key type %q, only \"oct\" is supported", j.Kty)
+	}
+
+	thumbprint, err := KeyThumbprint(j.K)
+	if err != nil {
+		return "", "", fmt.Errorf("crypto: JWK key is invalid: %w", err)
+	}
+
+	if j.Kid == "" {
+		return thumbprint, j.K, nil
+	}
+
+	return j.Kid, j.K, nil
+}
+
+// KeyToJWK exports a 256 bit encryption key as an "oct" JWK.
+func KeyToJWK(keyID, keyBase64URL string) ([]byte, error) {
+	jwk, err := newOctJWK(keyID, keyBase64URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return must(json.Marshal(jwk)), nil
+}
+
+// JWKToKey imports a 256 bit "oct" JWK as an encryption key. If the JWK has
+// no "kid", its thumbprint is used as the key ID.
+func JWKToKey(jwk []byte) (keyID, keyBase64URL string, err error) {
+	var j octJWK
+
+	if err := json.Unmarshal(jwk, &j); err != nil {
+		return "", "", err
+	}
+
+	return j.key()
+}
+
+// KeysToJWKS exports a map of key IDs to keys, as passed to Decrypt, as a
+// JWK Set. Keys are sorted by key ID.
+func KeysToJWKS(keys map[string]string) ([]byte, error) {
+	set := octJWKS{
+		Keys: make([]octJWK, 0, len(keys)),
+	}
+
+	for keyID, keyBase64URL := range keys {
+		jwk, err := newOctJWK(keyID, keyBase64URL)
+		if err != nil {
+			return nil, err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return must(json.Marshal(set)), nil
+}
+
+// JWKSToKeys imports a JWK Set of "oct" keys as a map of key IDs to keys,
+// which can be passed to Decrypt.
+func JWKSToKeys(jwks []byte) (map[string]string, error) {
+	var set octJWKS
+
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]string, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		keyID, keyBase64URL, err := jwk.key()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := keys[keyID]; ok {
+			return nil, fmt.Errorf("crypto: JWK Set contains key ID %q more than once", keyID)
+		}
+
+		keys[keyID] = keyBase64URL
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyThumbprint(t *testing.T) {
+	thumbprint, err := KeyThumbprint("pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	require.NoError(t, err)
+
+	expected := sha256.Sum256([]byte(`{"k":"pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4","kty":"oct"}`))
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(expected[:]), thumbprint)
+
+	// not base64
+	_, err = KeyThumbprint("!!!")
+	require.Error(t, err)
+
+	// short key
+	_, err = KeyThumbprint("AQAB")
+	require.Error(t, err)
+}
+
+func TestGenerateKeyWithThumbprint(t *testing.T) {
+	key, thumbprint, err := GenerateKeyWithThumbprint()
+	require.NoError(t, err)
+	require.Len(t, key, 43)
+	require.Len(t, thumbprint, 43)
+
+	expected, err := KeyThumbprint(key)
+	require.NoError(t, err)
+	require.Equal(t, expected, thumbprint)
+
+	otherKey, otherThumbprint, err := GenerateKeyWithThumbprint()
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey)
+	require.NotEqual(t, thumbprint, otherThumbprint)
+
+	_, err = NewEncryptedString("id", []byte("data"), thumbprint, key)
+	require.NoError(t, err)
+}
+
+func TestKeyToJWK(t *testing.T) {
+	jwk, err := KeyToJWK("key-id", "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kty":"oct","kid":"key-id","k":"pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"}`, string(jwk))
+
+	keyID, key, err := JWKToKey(jwk)
+	require.NoError(t, err)
+	require.Equal(t, "key-id", keyID)
+	require.Equal(t, "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", key)
+
+	// without a kid the thumbprint is used
+	keyID, _, err = JWKToKey([]byte(`{"kty":"oct","k":"pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"}`))
+	require.NoError(t, err)
+	require.Equal(t, must(KeyThumbprint("pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4")), keyID)
+
+	_, err = KeyToJWK("key-id", "!!!")
+	require.Error(t, err)
+
+	_, err = KeyToJWK("key-id", "AQAB")
+	require.Error(t, err)
+
+	negativeExamples := []string{
+		`not json`,
+		`{"kty":"RSA","kid":"key-id","n":"AQAB","e":"AQAB"}`,
+		`{"kty":"oct","kid":"key-id","k":"AQAB"}`,
+		`{"kty":"oct","kid":"key-id"}`,
+	}
+
+	for _, example := range negativeExamples {
+		_, _, err := JWKToKey([]byte(example))
+		require.Error(t, err, example)
+	}
+}
+
+func TestKeysToJWKS(t *testing.T) {
+	keys := map[string]string{
+		"new-key": "VgHBNAi2-n-UFTe3lZnxXMvCQHhx9LCY-Th3GmG1FUQ",
+		"old-key": "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4",
+	}
+
+	jwks, err := KeysToJWKS(keys)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"keys":[{"kty":"oct","kid":"new-key","k":"VgHBNAi2-n-UFTe3lZnxXMvCQHhx9LCY-Th3GmG1FUQ"},{"kty":"oct","kid":"old-key","k":"pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"}]}`, string(jwks))
+
+	imported, err := JWKSToKeys(jwks)
+	require.NoError(t, err)
+	require.Equal(t, keys, imported)
+
+	_, err = KeysToJWKS(map[string]string{"key-id": "AQAB"})
+	require.Error(t, err)
+
+	negativeExamples := []string{
+		`not json`,
+		`{"keys":[{"kty":"oct","kid":"key-id","k":"AQAB"}]}`,
+		`{"keys":[{"kty":"oct","kid":"key-id","k":"VgHBNAi2-n-UFTe3lZnxXMvCQHhx9LCY-Th3GmG1FUQ"},{"kty":"oct","kid":"key-id","k":"pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"}]}`,
+	}
+
+	for _, example := range negativeExamples {
+		_, err := JWKSToKeys([]byte(example))
+		require.Error(t, err, example)
+	}
+}
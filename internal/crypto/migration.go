@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EncryptionReport summarizes a set of stored values ahead of a key
+// rotation or algorithm migration.
+type EncryptionReport struct {
+	TotalRecords int `json:"total_records"`
+
+	// Unencrypted counts values that are not encrypted strings.
+	Unencrypted int `json:"unencrypted"`
+
+	ByKeyID     map[string]int `json:"by_key_id"`
+	ByAlgorithm map[string]int `json:"by_algorithm"`
+
+	// NeedsReEncryption counts values that are not encrypted with the
+	// current key and algorithm, including unencrypted ones.
+	NeedsReEncryption int `json:"needs_re_encryption"`
+
+	EstimatedMigrationTimeSec float64 `json:"estimated_migration_time_sec"`
+}
+
+// AnalyzeEncryptedStrings builds an EncryptionReport for the values as
+// stored in the database. The estimated migration time is derived from
+// recordsPerSecond, the measured re-encryption throughput; it is left at 0
+// when the throughput is not positive.
+func AnalyzeEncryptedStrings(values []string, encryptionKeyID, algorithm string, recordsPerSecond float64) EncryptionReport {
+	report := EncryptionReport{
+		TotalRecords: len(values),
+		ByKeyID:      make(map[string]int),
+		ByAlgorithm:  make(map[string]int),
+	}
+
+	for _, value := range values {
+		es := ParseEncryptedString(value)
+		if es == nil {
+			report.Unencrypted += 1
+			report.NeedsReEncryption += 1
+			continue
+		}
+
+		report.ByKeyID[es.KeyID] += 1
+		report.ByAlgorithm[es.Algorithm] += 1
+
+		if es.ShouldReEncrypt(encryptionKeyID, WithAlgorithm(algorithm)) {
+			report.NeedsReEncryption += 1
+		}
+	}
+
+	if recordsPerSecond > 0 {
+		report.EstimatedMigrationTimeSec = float64(report.NeedsReEncryption) / recordsPerSecond
+	}
+
+	return report
+}
+
+// ErrAlreadyRotated is returned when rotating a value that is already
+// encrypted with the new key and algorithm.
+var ErrAlreadyRotated = errors.New("crypto: encrypted string is already encrypted with the new key and algorithm")
+
+// RotateEncryptedString decrypts the value belonging to the object with the
+// given ID and encrypts it again with the new key. The original is not
+// modified, so callers can keep serving it until the rotated value is
+// stored. The options apply to both decryption and encryption, so a value
+// can be moved to a new algorithm while keeping the same key.
+func RotateEncryptedString(id string, es *EncryptedString, decryptionKeys map[string]string, newKeyID, newKeyBase64URL string, opts ...EncryptedStringOption) (*EncryptedString, error) {
+	if es == nil {
+		return nil, errors.New("crypto: no encrypted string to rotate")
+	}
+
+	if !es.ShouldReEncrypt(newKeyID, opts...) {
+		return nil, fmt.Errorf("%w: %q", ErrAlreadyRotated, newKeyID)
+	}
+
+	data, err := es.Decrypt(id, decryptionKeys, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEncryptedString(id, data, newKeyID, newKeyBase64URL, opts...)
+}
+
+// RotateResult holds the outcome of rotating a single record.
+type RotateResult struct {
+	ID        string
+	Encrypted *EncryptedString
+	Err       error
+}
+
+// RotateBatch rotates each record with RotateEncryptedString. Failures,
+// including records without an encrypted string, are reported per record
+// and do not stop the rest of the batch. Results are returned in the same
+// order as the records.
+func RotateBatch(records []BulkDecryptRecord, decryptionKeys map[string]string, newKeyID, newKeyBase64URL string, opts ...EncryptedStringOption) []RotateResult {
+	results := make([]RotateResult, len(records))
+
+	for i, record := range records {
+		results[i].ID = record.ID
+
+		if record.Encrypted == nil {
+			results[i].Err = errors.New("crypto: bulk rotate record has no encrypted string")
+			continue
+		}
+
+		results[i].Encrypted, results[i].Err = RotateEncryptedString(record.ID, record.Encrypted, decryptionKeys, newKeyID, newKeyBase64URL, opts...)
+	}
+
+	return results
+}
@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeEncryptedStrings(t *testing.T) {
+	encrypt := func(keyID string, opts ...EncryptedStringOption) string {
+		es, err := NewEncryptedString(uuid.Must(uuid.NewV4()).String(), []byte("data"), keyID, "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4", opts...)
+		require.NoError(t, err)
+
+		return es.String()
+	}
+
+	values := []string{
+		encrypt("new-key"),
+		encrypt("new-key"),
+		encrypt("new-key", WithAlgorithm(AlgorithmXChaCha20Poly1305HKDF)),
+		encrypt("old-key"),
+		"not-encrypted",
+	}
+
+	report := AnalyzeEncryptedStrings(values, "new-key", AlgorithmAESGCMHKDF, 2)
+
+	require.Equal(t, EncryptionReport{
+		TotalRecords: 5,
+		Unencrypted:  1,
+		ByKeyID: map[string]int{
+			"new-key": 3,
+			"old-key": 1,
+		},
+		ByAlgorithm: map[string]int{
+			AlgorithmAESGCMHKDF:            3,
+			AlgorithmXChaCha20Poly1305HKDF: 1,
+		},
+		NeedsReEncryption:         3,
+		EstimatedMigrationTimeSec: 1.5,
+	}, report)
+
+	report = AnalyzeEncryptedStrings(values, "new-key", AlgorithmAESGCMHKDF, 0)
+	require.Equal(t, float64(0), report.EstimatedMigrationTimeSec)
+}
+
+func TestRotateEncryptedString(t *testing.T) {
+	oldKey := "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"
+	newKey := "VgHBNAi2-n-UFTe3lZnxXMvCQHhx9LCY-Th3GmG1FUQ"
+
+	keys := map[string]string{
+		"old-key": oldKey,
+		"new-key": newKey,
+	}
+
+	id := uuid.Must(uuid.NewV4()).String()
+
+	es, err := NewEncryptedString(id, []byte("data"), "old-key", oldKey)
+	require.NoError(t, err)
+
+	original := es.String()
+
+	rotated, err := RotateEncryptedString(id, es, keys, "new-key", newKey)
+	require.NoError(t, err)
+	require.Equal(t, "new-key", rotated.KeyID)
+	require.Equal(t, original, es.String())
+
+	decrypted, err := rotated.Decrypt(id, keys)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), decrypted)
+
+	_, err = RotateEncryptedString(id, rotated, keys, "new-key", newKey)
+	require.ErrorIs(t, err, ErrAlreadyRotated)
+
+	_, err = RotateEncryptedString(id, es, map[string]string{}, "new-key", newKey)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = RotateEncryptedString(id, nil, keys, "new-key", newKey)
+	require.Error(t, err)
+
+	migrated, err := RotateEncryptedString(id, rotated, keys, "new-key", newKey, WithAlgorithm(AlgorithmChaCha20Poly1305HKDF))
+	require.NoError(t, err)
+	require.Equal(t, "new-key", migrated.KeyID)
+	require.Equal(t, AlgorithmChaCha20Poly1305HKDF, migrated.Algorithm)
+	require.Equal(t, AlgorithmAESGCMHKDF, rotated.Algorithm)
+
+	decrypted, err = migrated.Decrypt(id, keys)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), decrypted)
+
+	_, err = RotateEncryptedString(id, migrated, keys, "new-key", newKey, WithAlgorithm(AlgorithmChaCha20Poly1305HKDF))
+	require.ErrorIs(t, err, ErrAlreadyRotated)
+}
+
+func TestRotateBatch(t *testing.T) {
+	oldKey := "pwFoiPyybQMqNmYVN0gUnpbfpGQV2sDv9vp0ZAxi_Y4"
+	newKey := "VgHBNAi2-n-UFTe3lZnxXMvCQHhx9LCY-Th3GmG1FUQ"
+
+	keys := map[string]string{
+		"old-key": oldKey,
+	}
+
+	records := make([]BulkDecryptRecord, 4)
+
+	for i := range records[:3] {
+		id := uuid.Must(uuid.NewV4()).String()
+
+		es, err := NewEncryptedString(id, []byte("data"), "old-key", oldKey)
+		require.NoError(t, err)
+
+		records[i] = BulkDecryptRecord{ID: id, Encrypted: es}
+	}
+
+	// encrypted for another ID, so decryption fails
+	records[1].ID = uuid.Must(uuid.NewV4()).String()
+
+	// NULL column
+	records[3].ID = uuid.Must(uuid.NewV4()).String()
+
+	results := RotateBatch(records, keys, "new-key", newKey)
+	require.Len(t, results, 4)
+
+	for i, result := range results {
+		require.Equal(t, records[i].ID, result.ID)
+
+		if i == 3 {
+			require.Error(t, result.Err)
+			require.Nil(t, result.Encrypted)
+			continue
+		}
+
+		if i == 1 {
+			require.ErrorIs(t, result.Err, ErrDecryptionFailed)
+			require.Nil(t, result.Encrypted)
+			continue
+		}
+
+		require.NoError(t, result.Err)
+
+		decrypted, err := result.Encrypted.Decrypt(result.ID, map[string]string{"new-key": newKey})
+		require.NoError(t, err)
+		require.Equal(t, []byte("data"), decrypted)
+	}
+}
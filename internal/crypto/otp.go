@@ -0,0 +1,265 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOTPExpired is returned when verifying an OTP session past its expiry.
+	ErrOTPExpired = errors.New("crypto: OTP has expired")
+
+	// ErrOTPMaxAttempts is returned when an OTP session or bundle ran out
+	// of verification attempts.
+	ErrOTPMaxAttempts = errors.New("crypto: too many OTP verification attempts")
+
+	// ErrOTPTooManyAttempts is an alias of ErrOTPMaxAttempts.
+	ErrOTPTooManyAttempts = ErrOTPMaxAttempts
+
+	// ErrOTPMismatch is returned when the code does not match the OTP.
+	ErrOTPMismatch = errors.New("crypto: OTP does not match")
+
+	// ErrOTPSessionInvalidated is returned when verifying an OTP session
+	// that was already used successfully.
+	ErrOTPSessionInvalidated = errors.New("crypto: OTP session is no longer valid")
+
+	// ErrOTPRateLimited is returned when an identifier exceeded its OTP
+	// rate limit. The error is an *OTPRateLimitError.
+	ErrOTPRateLimited = errors.New("crypto: OTP rate limit exceeded")
+)
+
+// OTPDelivery describes an OTP and the state of its delivery.
+type OTPDelivery struct {
+	Code         string
+	Channel      string
+	DeliveredAt  time.Time
+	AttemptCount int
+	MaxAttempts  int
+	ExpiresAt    time.Time
+}
+
+// OTPSession tracks an OTP from generation until it is successfully
+// verified, expires or runs out of attempts. It is safe for concurrent use.
+type OTPSession struct {
+	mu          sync.Mutex
+	delivery    OTPDelivery
+	invalidated bool
+
+	now func() time.Time
+}
+
+// NewOTPSession generates a digits long OTP to be delivered over channel
+// (e.g. "sms" or "email"), which is valid for ttl and can be checked at
+// most maxAttempts times.
+func NewOTPSession(digits int, channel string, ttl time.Duration, maxAttempts int) (*OTPSession, error) {
+	if digits < 1 || digits > 10 {
+		return nil, errors.New("crypto: OTP must have between 1 and 10 digits")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("crypto: OTP TTL must be positive")
+	}
+
+	if maxAttempts < 1 {
+		return nil, errors.New("crypto: OTP must allow at least 1 attempt")
+	}
+
+	s := &OTPSession{
+		now: time.Now,
+	}
+
+	s.delivery = OTPDelivery{
+		Code:        GenerateOtp(digits),
+		Channel:     channel,
+		MaxAttempts: maxAttempts,
+		ExpiresAt:   s.now().Add(ttl),
+	}
+
+	return s, nil
+}
+
+// Delivery returns a snapshot of the OTP and its delivery state.
+func (s *OTPSession) Delivery() OTPDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.delivery
+}
+
+// MarkDelivered records that the OTP was sent to the user.
+func (s *OTPSession) MarkDelivered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delivery.DeliveredAt = s.now()
+}
+
+// Verify checks the code against the OTP. Every call counts as an attempt,
+// and the session can't be used again after a successful verification.
+func (s *OTPSession) Verify(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.invalidated {
+		return ErrOTPSessionInvalidated
+	}
+
+	if !s.now().Before(s.delivery.ExpiresAt) {
+		return ErrOTPExpired
+	}
+
+	if s.delivery.AttemptCount >= s.delivery.MaxAttempts {
+		return ErrOTPMaxAttempts
+	}
+
+	s.delivery.AttemptCount += 1
+
+	if !SecureCompareString(GenerateTokenHash("", code), GenerateTokenHash("", s.delivery.Code)) {
+		return ErrOTPMismatch
+	}
+
+	s.invalidated = true
+
+	return nil
+}
+
+// OTPBundle is an OTP together with the metadata needed to verify it later,
+// meant to be stored in a database row. Only the hash of the code is
+// serialized; Code is set when the bundle is generated so it can be sent
+// to the user.
+type OTPBundle struct {
+	Code         string    `json:"-"`
+	TokenHash    string    `json:"token_hash"`
+	IssuedAt     time.Time `json:"issued_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	AttemptCount int       `json:"attempt_count"`
+}
+
+// GenerateOtpBundle generates a digits long OTP that is valid for ttl.
+func GenerateOtpBundle(digits int, ttl time.Duration) OTPBundle {
+	code := GenerateOtp(digits)
+	now := time.Now()
+
+	return OTPBundle{
+		Code:      code,
+		TokenHash: GenerateTokenHash("", code),
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+// VerifyOtpBundle checks the candidate code against the bundle. It does not
+// modify the bundle; callers must increment and store AttemptCount after
+// every verification so maxAttempts is enforced.
+//
+// When limiter is not nil, every verification is recorded as an attempt
+// for identifier (e.g. the email or phone) and rejected with an
+// *OTPRateLimitError once the limit is reached.
+func VerifyOtpBundle(bundle OTPBundle, candidate string, maxAttempts int, identifier string, limiter OTPRateLimiter) error {
+	if limiter != nil {
+		if allowed, _, resetAt := limiter.RecordAttempt(identifier); !allowed {
+			return &OTPRateLimitError{RetryAfter: time.Until(resetAt)}
+		}
+	}
+
+	if !time.Now().Before(bundle.ExpiresAt) {
+		return ErrOTPExpired
+	}
+
+	if bundle.AttemptCount >= maxAttempts {
+		return ErrOTPMaxAttempts
+	}
+
+	if !SecureCompareString(GenerateTokenHash("", candidate), bundle.TokenHash) {
+		return ErrOTPMismatch
+	}
+
+	return nil
+}
+
+// OTPRateLimitError is returned when an identifier exceeded its OTP rate
+// limit. It matches ErrOTPRateLimited with errors.Is.
+type OTPRateLimitError struct {
+	// RetryAfter is how long until the next attempt is allowed.
+	RetryAfter time.Duration
+}
+
+func (e *OTPRateLimitError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrOTPRateLimited, e.RetryAfter)
+}
+
+func (e *OTPRateLimitError) Unwrap() error {
+	return ErrOTPRateLimited
+}
+
+// OTPRateLimitPolicy allows at most MaxAttempts OTP attempts per identifier
+// within any sliding Window.
+type OTPRateLimitPolicy struct {
+	MaxAttempts int
+	Window      time.Duration
+}
+
+// OTPRateLimiter limits how many OTPs are generated or verified for an
+// identifier, such as an email or phone.
+//
+// RecordAttempt must atomically record an attempt for the identifier if it
+// is allowed. It returns whether the attempt is allowed, how many attempts
+// remain after it and when the oldest attempt in the window expires.
+type OTPRateLimiter interface {
+	RecordAttempt(identifier string) (allowed bool, remaining int, resetAt time.Time)
+}
+
+// MemoryOTPRateLimiter is an OTPRateLimiter that keeps attempts in memory.
+// It is only suitable for a single server process; deployments with
+// multiple instances need a shared limiter. Expired attempts are pruned
+// when their identifier is recorded again.
+type MemoryOTPRateLimiter struct {
+	mu       sync.Mutex
+	policy   OTPRateLimitPolicy
+	attempts map[string][]time.Time
+
+	now func() time.Time
+}
+
+// NewMemoryOTPRateLimiter creates a MemoryOTPRateLimiter enforcing policy.
+func NewMemoryOTPRateLimiter(policy OTPRateLimitPolicy) (*MemoryOTPRateLimiter, error) {
+	if policy.MaxAttempts < 1 {
+		return nil, errors.New("crypto: OTP rate limit must allow at least 1 attempt")
+	}
+
+	if policy.Window <= 0 {
+		return nil, errors.New("crypto: OTP rate limit window must be positive")
+	}
+
+	limiter := &MemoryOTPRateLimiter{
+		policy:   policy,
+		attempts: make(map[string][]time.Time),
+		now:      time.Now,
+	}
+
+	return limiter, nil
+}
+
+func (l *MemoryOTPRateLimiter) RecordAttempt(identifier string) (bool, int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	windowStart := now.Add(-l.policy.Window)
+
+	attempts := l.attempts[identifier]
+	for len(attempts) > 0 && !attempts[0].After(windowStart) {
+		attempts = attempts[1:]
+	}
+
+	if len(attempts) >= l.policy.MaxAttempts {
+		return false, 0, attempts[0].Add(l.policy.Window)
+	}
+
+	attempts = append(attempts, now)
+	l.attempts[identifier] = attempts
+
+	return true, l.policy.MaxAttempts - len(attempts), attempts[0].Add(l.policy.Window)
+}
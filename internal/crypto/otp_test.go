@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOTPSession(t *testing.T) {
+	s, err := NewOTPSession(6, "sms", time.Minute, 3)
+	require.NoError(t, err)
+
+	delivery := s.Delivery()
+	assert.Len(t, delivery.Code, 6)
+	assert.Equal(t, "sms", delivery.Channel)
+	assert.Equal(t, 3, delivery.MaxAttempts)
+	assert.Equal(t, 0, delivery.AttemptCount)
+	assert.True(t, delivery.DeliveredAt.IsZero())
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.ExpiresAt, time.Second)
+
+	s.MarkDelivered()
+	assert.False(t, s.Delivery().DeliveredAt.IsZero())
+
+	for _, example := range []struct {
+		digits      int
+		ttl         time.Duration
+		maxAttempts int
+	}{
+		{0, time.Minute, 3},
+		{11, time.Minute, 3},
+		{6, 0, 3},
+		{6, time.Minute, 0},
+	} {
+		_, err := NewOTPSession(example.digits, "sms", example.ttl, example.maxAttempts)
+		assert.Error(t, err)
+	}
+}
+
+func TestOTPSessionVerify(t *testing.T) {
+	s, err := NewOTPSession(6, "email", time.Minute, 3)
+	require.NoError(t, err)
+
+	code := s.Delivery().Code
+
+	assert.ErrorIs(t, s.Verify("wrong"), ErrOTPMismatch)
+	assert.Equal(t, 1, s.Delivery().AttemptCount)
+
+	assert.NoError(t, s.Verify(code))
+	assert.Equal(t, 2, s.Delivery().AttemptCount)
+
+	assert.ErrorIs(t, s.Verify(code), ErrOTPSessionInvalidated)
+}
+
+func TestOTPSessionTooManyAttempts(t *testing.T) {
+	s, err := NewOTPSession(6, "sms", time.Minute, 2)
+	require.NoError(t, err)
+
+	code := s.Delivery().Code
+
+	assert.ErrorIs(t, s.Verify("wrong"), ErrOTPMismatch)
+	assert.ErrorIs(t, s.Verify("wrong"), ErrOTPMismatch)
+	assert.ErrorIs(t, s.Verify(code), ErrOTPTooManyAttempts)
+	assert.Equal(t, 2, s.Delivery().AttemptCount)
+}
+
+func TestOTPSessionExpired(t *testing.T) {
+	s, err := NewOTPSession(6, "sms", time.Minute, 3)
+	require.NoError(t, err)
+
+	s.now = func() time.Time {
+		return time.Now().Add(time.Minute)
+	}
+
+	assert.ErrorIs(t, s.Verify(s.Delivery().Code), ErrOTPExpired)
+	assert.Equal(t, 0, s.Delivery().AttemptCount)
+}
+
+func TestOTPSessionConcurrentAttempts(t *testing.T) {
+	s, err := NewOTPSession(6, "sms", time.Minute, 5)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i += 1 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_ = s.Verify("wrong")
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 5, s.Delivery().AttemptCount)
+}
+
+func TestOTPBundle(t *testing.T) {
+	bundle := GenerateOtpBundle(6, time.Minute)
+
+	assert.Len(t, bundle.Code, 6)
+	assert.Equal(t, GenerateTokenHash("", bundle.Code), bundle.TokenHash)
+	assert.Equal(t, time.Minute, bundle.ExpiresAt.Sub(bundle.IssuedAt))
+	assert.Equal(t, 0, bundle.AttemptCount)
+
+	// the code itself is never serialized
+	serialized, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	assert.NotContains(t, string(serialized), `"`+bundle.Code+`"`)
+
+	var stored OTPBundle
+	require.NoError(t, json.Unmarshal(serialized, &stored))
+
+	assert.NoError(t, VerifyOtpBundle(stored, bundle.Code, 3, "", nil))
+	assert.ErrorIs(t, VerifyOtpBundle(stored, "wrong", 3, "", nil), ErrOTPMismatch)
+
+	stored.AttemptCount = 3
+	assert.ErrorIs(t, VerifyOtpBundle(stored, bundle.Code, 3, "", nil), ErrOTPMaxAttempts)
+
+	stored.AttemptCount = 0
+	stored.ExpiresAt = time.Now().Add(-time.Second)
+	assert.ErrorIs(t, VerifyOtpBundle(stored, bundle.Code, 3, "", nil), ErrOTPExpired)
+}
+
+func TestOTPBundleRateLimited(t *testing.T) {
+	bundle := GenerateOtpBundle(6, time.Minute)
+	limiter, err := NewMemoryOTPRateLimiter(OTPRateLimitPolicy{MaxAttempts: 2, Window: time.Minute})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, VerifyOtpBundle(bundle, "wrong", 5, "user@example.com", limiter), ErrOTPMismatch)
+	assert.NoError(t, VerifyOtpBundle(bundle, bundle.Code, 5, "user@example.com", limiter))
+
+	err = VerifyOtpBundle(bundle, bundle.Code, 5, "user@example.com", limiter)
+	assert.ErrorIs(t, err, ErrOTPRateLimited)
+
+	var rateLimitErr *OTPRateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.InDelta(t, time.Minute, rateLimitErr.RetryAfter, float64(time.Second))
+	assert.Contains(t, err.Error(), "retry after")
+
+	// other identifiers are not affected
+	assert.NoError(t, VerifyOtpBundle(bundle, bundle.Code, 5, "other@example.com", limiter))
+}
+
+func TestMemoryOTPRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter, err := NewMemoryOTPRateLimiter(OTPRateLimitPolicy{MaxAttempts: 2, Window: time.Minute})
+	require.NoError(t, err)
+	limiter.now = func() time.Time { return now }
+
+	allowed, remaining, resetAt := limiter.RecordAttempt("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 1, remaining)
+	assert.Equal(t, now.Add(time.Minute), resetAt)
+
+	now = now.Add(30 * time.Second)
+
+	allowed, remaining, resetAt = limiter.RecordAttempt("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, now.Add(30*time.Second), resetAt)
+
+	allowed, remaining, resetAt = limiter.RecordAttempt("a")
+	assert.False(t, allowed)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, now.Add(30*time.Second), resetAt)
+
+	allowed, _, _ = limiter.RecordAttempt("b")
+	assert.True(t, allowed)
+
+	// the first attempt leaves the sliding window
+	now = now.Add(30 * time.Second)
+
+	allowed, remaining, _ = limiter.RecordAttempt("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 0, remaining)
+
+	// expired attempts are pruned when the identifier is recorded
+	now = now.Add(time.Hour)
+
+	allowed, remaining, _ = limiter.RecordAttempt("a")
+	assert.True(t, allowed)
+	assert.Equal(t, 1, remaining)
+	assert.Len(t, limiter.attempts["a"], 1)
+}
+
+func TestNewMemoryOTPRateLimiterNegative(t *testing.T) {
+	policies := []OTPRateLimitPolicy{
+		{MaxAttempts: 0, Window: time.Minute},
+		{MaxAttempts: -1, Window: time.Minute},
+		{MaxAttempts: 1, Window: 0},
+		{MaxAttempts: 1, Window: -time.Minute},
+	}
+
+	for _, policy := range policies {
+		limiter, err := NewMemoryOTPRateLimiter(policy)
+		assert.Error(t, err, policy)
+		assert.Nil(t, limiter, policy)
+	}
+}
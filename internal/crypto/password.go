@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/subtle"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,6 +20,7 @@ import (
 
 	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
 	"golang.org/x/crypto/scrypt"
 )
 
@@ -35,6 +38,7 @@ const (
 
 	Argon2Prefix         = "$argon2"
 	FirebaseScryptPrefix = "$fbscrypt"
+	PBKDF2SHA256Prefix   = "$pbkdf2-sha256"
 	FirebaseScryptKeyLen = 32 // Firebase uses AES-256 which requires 32 byte keys: https://pkg.go.dev/golang.org/x/crypto/scrypt#Key
 )
 
@@ -55,9 +59,43 @@ var (
 
 var ErrArgon2MismatchedHashAndPassword = errors.New("crypto: argon2 hash and password mismatch")
 var ErrScryptMismatchedHashAndPassword = errors.New("crypto: fbscrypt hash and password mismatch")
+var ErrPBKDF2MismatchedHashAndPassword = errors.New("crypto: pbkdf2 hash and password mismatch")
+
+// Password hashing algorithms supported by HashPassword.
+const (
+	PasswordAlgorithmArgon2id     = "argon2id"
+	PasswordAlgorithmPBKDF2SHA256 = "pbkdf2-sha256"
+)
+
+// Argon2Params are the cost parameters of an argon2id hash.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// Argon2idParams are used by HashPassword for new argon2id hashes. The
+// defaults are the OWASP recommended minimum of 19 MiB of memory, 2
+// iterations and 1 degree of parallelism.
+var Argon2idParams = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+}
+
+// PBKDF2Iterations is used by HashPassword for new pbkdf2-sha256 hashes, as
+// recommended by OWASP. It must be between 1 and MaxPBKDF2Iterations, so
+// new hashes can always be verified.
+var PBKDF2Iterations = 600_000
+
+// MaxPBKDF2Iterations is the highest iteration count accepted in a
+// pbkdf2-sha256 hash, so a stored hash can't make each sign-in attempt
+// arbitrarily expensive.
+const MaxPBKDF2Iterations = 2_000_000
 
 // argon2HashRegexp https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md#argon2-encoding
 var argon2HashRegexp = regexp.MustCompile("^[$](?P<alg>argon2(d|i|id))[$]v=(?P<v>(16|19))[$]m=(?P<m>[0-9]+),t=(?P<t>[0-9]+),p=(?P<p>[0-9]+)(,keyid=(?P<keyid>[^,$]+))?(,data=(?P<data>[^$]+))?[$](?P<salt>[^$]*)[$](?P<hash>.*)$")
+var pbkdf2HashRegexp = regexp.MustCompile(`^\$pbkdf2-sha256\$i=(?P<i>[0-9]+)\$(?P<salt>[^$]+)\$(?P<hash>[^$]+)$`)
 var fbscryptHashRegexp = regexp.MustCompile(`^\$fbscrypt\$v=(?P<v>[0-9]+),n=(?P<n>[0-9]+),r=(?P<r>[0-9]+),p=(?P<p>[0-9]+)(?:,ss=(?P<ss>[^,]+))?(?:,sk=(?P<sk>[^$]+))?\$(?P<salt>[^$]+)\$(?P<hash>.+)$`)
 
 type Argon2HashInput struct {
@@ -72,6 +110,12 @@ type Argon2HashInput struct {
 	rawHash []byte
 }
 
+type PBKDF2HashInput struct {
+	iterations uint64
+	salt       []byte
+	rawHash    []byte
+}
+
 type FirebaseScryptHashInput struct {
 	v             string
 	memory        uint64
@@ -270,7 +314,7 @@ func compareHashAndPasswordArgon2(ctx context.Context, hash, password string) er
 		derivedKey = argon2.IDKey([]byte(password), input.salt, uint32(input.time), uint32(input.memory), uint8(input.threads), uint32(len(input.rawHash))) // #nosec G115
 	}
 
-	match = subtle.ConstantTimeCompare(derivedKey, input.rawHash) == 1
+	match = SecureCompareBytes(derivedKey, input.rawHash)
 
 	if !match {
 		return ErrArgon2MismatchedHashAndPassword
@@ -302,7 +346,7 @@ func compareHashAndPasswordFirebaseScrypt(ctx context.Context, hash, password st
 
 	derivedKey := firebaseScrypt([]byte(password), input.salt, input.signerKey, input.saltSeparator, input.memory, input.rounds, input.threads)
 
-	match = subtle.ConstantTimeCompare(derivedKey, input.rawHash) == 1
+	match = SecureCompareBytes(derivedKey, input.rawHash)
 	if !match {
 		return ErrScryptMismatchedHashAndPassword
 	}
@@ -323,6 +367,75 @@ func firebaseScrypt(password, salt, signerKey, saltSeparator []byte, memCost, ro
 	return cipherText[aes.BlockSize:]
 }
 
+// ParsePBKDF2Hash parses a $pbkdf2-sha256$i=<iterations>$<salt>$<hash>
+// hash, with the salt and hash encoded in unpadded base64.
+func ParsePBKDF2Hash(hash string) (*PBKDF2HashInput, error) {
+	submatch := pbkdf2HashRegexp.FindStringSubmatchIndex(hash)
+	if submatch == nil {
+		return nil, errors.New("crypto: incorrect pbkdf2 hash format")
+	}
+
+	i := string(pbkdf2HashRegexp.ExpandString(nil, "$i", hash, submatch))
+	saltB64 := string(pbkdf2HashRegexp.ExpandString(nil, "$salt", hash, submatch))
+	hashB64 := string(pbkdf2HashRegexp.ExpandString(nil, "$hash", hash, submatch))
+
+	iterations, err := strconv.ParseUint(i, 10, 32)
+	if err != nil || iterations == 0 {
+		return nil, fmt.Errorf("crypto: pbkdf2 hash has invalid i parameter %q", i)
+	}
+
+	if iterations > MaxPBKDF2Iterations {
+		return nil, fmt.Errorf("crypto: pbkdf2 hash has i=%d above the maximum of %d", iterations, MaxPBKDF2Iterations)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(saltB64)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: pbkdf2 hash has invalid base64 in the salt section %w", err)
+	}
+
+	rawHash, err := base64.RawStdEncoding.DecodeString(hashB64)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: pbkdf2 hash has invalid base64 in the hash section %w", err)
+	}
+
+	input := &PBKDF2HashInput{
+		iterations: iterations,
+		salt:       salt,
+		rawHash:    rawHash,
+	}
+
+	return input, nil
+}
+
+func compareHashAndPasswordPBKDF2(ctx context.Context, hash, password string) error {
+	input, err := ParsePBKDF2Hash(hash)
+	if err != nil {
+		return err
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("alg", PasswordAlgorithmPBKDF2SHA256),
+		attribute.Int64("i", int64(input.iterations)),
+		attribute.Int("len", len(input.rawHash)),
+	} // #nosec G115
+
+	var match bool
+	compareHashAndPasswordSubmittedCounter.Add(ctx, 1, metric.WithAttributes(attributes...))
+	defer func() {
+		attributes = append(attributes, attribute.Bool("match", match))
+		compareHashAndPasswordCompletedCounter.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}()
+
+	derivedKey := pbkdf2.Key([]byte(password), input.salt, int(input.iterations), len(input.rawHash), sha256.New) // #nosec G115
+
+	match = SecureCompareBytes(derivedKey, input.rawHash)
+	if !match {
+		return ErrPBKDF2MismatchedHashAndPassword
+	}
+
+	return nil
+}
+
 // CompareHashAndPassword compares the hash and
 // password, returns nil if equal otherwise an error. Context can be used to
 // cancel the hashing if the algorithm supports it.
@@ -331,6 +444,8 @@ func CompareHashAndPassword(ctx context.Context, hash, password string) error {
 		return compareHashAndPasswordArgon2(ctx, hash, password)
 	} else if strings.HasPrefix(hash, FirebaseScryptPrefix) {
 		return compareHashAndPasswordFirebaseScrypt(ctx, hash, password)
+	} else if strings.HasPrefix(hash, PBKDF2SHA256Prefix) {
+		return compareHashAndPasswordPBKDF2(ctx, hash, password)
 	}
 
 	// assume bcrypt
@@ -382,6 +497,62 @@ func GenerateFromPassword(ctx context.Context, password string) (string, error)
 	return string(hash), nil
 }
 
+// HashPassword hashes the password with argon2id or pbkdf2-sha256, encoded in
+// the PHC string format. The cost parameters are taken from Argon2idParams
+// and PBKDF2Iterations.
+func HashPassword(password string, algorithm string) (string, error) {
+	salt := make([]byte, 16)
+	must(io.ReadFull(rand.Reader, salt))
+
+	switch algorithm {
+	case PasswordAlgorithmArgon2id:
+		params := Argon2idParams
+		key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, 32)
+
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+
+	case PasswordAlgorithmPBKDF2SHA256:
+		if PBKDF2Iterations < 1 || PBKDF2Iterations > MaxPBKDF2Iterations {
+			return "", fmt.Errorf("crypto: pbkdf2 iterations %d must be between 1 and %d", PBKDF2Iterations, MaxPBKDF2Iterations)
+		}
+
+		key := pbkdf2.Key([]byte(password), salt, PBKDF2Iterations, 32, sha256.New)
+
+		return fmt.Sprintf("%s$i=%d$%s$%s", PBKDF2SHA256Prefix, PBKDF2Iterations, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+
+	return "", fmt.Errorf("crypto: unsupported password hashing algorithm %q", algorithm)
+}
+
+// VerifyPassword checks the password against a hash in any format supported
+// by CompareHashAndPassword. When the password matches, needsUpgrade tells
+// you if the hash should be replaced with a new HashPassword argon2id hash,
+// because it uses another algorithm or weaker parameters than
+// Argon2idParams. A mismatch is not an error.
+func VerifyPassword(password, hash string) (match bool, needsUpgrade bool, err error) {
+	err = CompareHashAndPassword(context.Background(), hash, password)
+	switch {
+	case errors.Is(err, ErrArgon2MismatchedHashAndPassword),
+		errors.Is(err, ErrScryptMismatchedHashAndPassword),
+		errors.Is(err, ErrPBKDF2MismatchedHashAndPassword),
+		errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, false, nil
+
+	case err != nil:
+		return false, false, err
+	}
+
+	input, err := ParseArgon2Hash(hash)
+	if err != nil || input.alg != PasswordAlgorithmArgon2id {
+		return true, true, nil
+	}
+
+	params := Argon2idParams
+	needsUpgrade = input.memory < uint64(params.Memory) || input.time < uint64(params.Iterations) || input.threads < uint64(params.Parallelism)
+
+	return true, needsUpgrade, nil
+}
+
 func GeneratePassword(requiredChars []string, length int) string {
 	passwordBuilder := strings.Builder{}
 	passwordBuilder.Grow(length)
@@ -21,7 +21,7 @@ func TestArgon2(t *testing.T) {
 	}
 
 	for _, example := range examples {
-		assert.Error(t, CompareHashAndPassword(context.Background(), example, "test1"))
+		assert.ErrorIs(t, CompareHashAndPassword(context.Background(), example, "test1"), ErrArgon2MismatchedHashAndPassword)
 	}
 
 	negativeExamples := []string{
@@ -120,7 +120,7 @@ func TestFirebaseScrypt(t *testing.T) {
 	}
 
 	for _, example := range examples {
-		assert.Error(t, CompareHashAndPassword(context.Background(), example, "mytestpassword1"))
+		assert.ErrorIs(t, CompareHashAndPassword(context.Background(), example, "mytestpassword1"), ErrScryptMismatchedHashAndPassword)
 	}
 
 	negativeExamples := []string{
@@ -176,3 +176,94 @@ func TestBcrypt(t *testing.T) {
 		assert.Error(t, CompareHashAndPassword(context.Background(), example, "test"))
 	}
 }
+
+func TestPBKDF2(t *testing.T) {
+	// uses the `test` password
+	example := "$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE"
+
+	assert.NoError(t, CompareHashAndPassword(context.Background(), example, "test"))
+	assert.ErrorIs(t, CompareHashAndPassword(context.Background(), example, "test1"), ErrPBKDF2MismatchedHashAndPassword)
+
+	negativeExamples := []string{
+		// missing iterations
+		"$pbkdf2-sha256$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		// iterations is 0
+		"$pbkdf2-sha256$i=0$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		// iterations not 32 bits
+		"$pbkdf2-sha256$i=4294967297$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		// iterations above the maximum
+		"$pbkdf2-sha256$i=2000001$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		// salt not base64
+		"$pbkdf2-sha256$i=1000$!!!$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		// hash not base64
+		"$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$!!!",
+	}
+
+	for _, example := range negativeExamples {
+		assert.Error(t, CompareHashAndPassword(context.Background(), example, "test"))
+
+		_, err := ParsePBKDF2Hash(example)
+		assert.Error(t, err)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	defaultArgon2idParams := Argon2idParams
+	defaultPBKDF2Iterations := PBKDF2Iterations
+
+	defer func() {
+		Argon2idParams = defaultArgon2idParams
+		PBKDF2Iterations = defaultPBKDF2Iterations
+	}()
+
+	PBKDF2Iterations = 1000
+
+	argon2idHash, err := HashPassword("test", PasswordAlgorithmArgon2id)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(argon2idHash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	pbkdf2Hash, err := HashPassword("test", PasswordAlgorithmPBKDF2SHA256)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pbkdf2Hash, "$pbkdf2-sha256$i=1000$"))
+
+	_, err = HashPassword("test", "md5")
+	assert.Error(t, err)
+
+	// iteration counts that VerifyPassword would reject are refused
+	for _, iterations := range []int{0, -1, MaxPBKDF2Iterations + 1} {
+		PBKDF2Iterations = iterations
+
+		_, err = HashPassword("test", PasswordAlgorithmPBKDF2SHA256)
+		assert.Error(t, err, iterations)
+	}
+
+	PBKDF2Iterations = 1000
+
+	match, needsUpgrade, err := VerifyPassword("test", argon2idHash)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.False(t, needsUpgrade)
+
+	match, needsUpgrade, err = VerifyPassword("test", pbkdf2Hash)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, needsUpgrade)
+
+	for _, hash := range []string{argon2idHash, pbkdf2Hash} {
+		match, needsUpgrade, err = VerifyPassword("test1", hash)
+		assert.NoError(t, err)
+		assert.False(t, match)
+		assert.False(t, needsUpgrade)
+	}
+
+	// hashes with weaker parameters than the current ones need an upgrade
+	Argon2idParams.Iterations = 3
+
+	match, needsUpgrade, err = VerifyPassword("test", argon2idHash)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, needsUpgrade)
+
+	_, _, err = VerifyPassword("test", "$argon2id$v=16$m=16,t=2,p=1$bGJRWThNOHJJTVBSdHl2dQ$NfEnUOuUpb7F2fQkgFUG4g")
+	assert.Error(t, err)
+}
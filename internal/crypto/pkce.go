@@ -0,0 +1,26 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// GeneratePKCEVerifier returns a new random PKCE code verifier (RFC 7636),
+// a 43 character base64url string encoding 32 random bytes.
+func GeneratePKCEVerifier() string {
+	return SecureTokenN(32)
+}
+
+// ComputePKCEChallenge returns the S256 code challenge for the verifier,
+// BASE64URL(SHA256(verifier)) without padding.
+func ComputePKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPKCEChallenge tells you if the S256 code challenge was computed
+// from the verifier. The comparison is done in constant time.
+func VerifyPKCEChallenge(verifier, challenge string) bool {
+	return SecureCompareString(ComputePKCEChallenge(verifier), challenge)
+}
@@ -0,0 +1,23 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPKCE(t *testing.T) {
+	// RFC 7636, Appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.Equal(t, challenge, ComputePKCEChallenge(verifier))
+	assert.True(t, VerifyPKCEChallenge(verifier, challenge))
+	assert.False(t, VerifyPKCEChallenge(verifier+"x", challenge))
+	assert.False(t, VerifyPKCEChallenge(verifier, verifier))
+
+	generated := GeneratePKCEVerifier()
+	assert.Len(t, generated, 43)
+	assert.NotEqual(t, generated, GeneratePKCEVerifier())
+	assert.True(t, VerifyPKCEChallenge(generated, ComputePKCEChallenge(generated)))
+}
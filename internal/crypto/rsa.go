@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// minRSAKeyBits is the smallest RSA key NewEncryptedStringRSA encrypts to.
+const minRSAKeyBits = 2048
+
+// NewEncryptedStringRSA encrypts data belonging to the object with the given
+// ID to the PEM encoded RSA public key using RSA-OAEP with SHA-256, so only
+// the holder of the private key can decrypt it. Decrypt expects the PEM
+// encoded private key in place of the symmetric key.
+//
+// RSA-OAEP can only encrypt a few hundred bytes, depending on the key size.
+// The key namespace, compression and purpose options apply, the others are
+// ignored.
+func NewEncryptedStringRSA(id string, data []byte, keyID string, pubKeyPEM []byte, opts ...EncryptedStringOption) (*EncryptedString, error) {
+	options := newEncryptedStringOptions(opts)
+
+	publicKey, err := parseRSAPublicKeyPEM(pubKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	if publicKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("crypto: RSA key with ID %q must be at least %d bits", keyID, minRSAKeyBits)
+	}
+
+	es := EncryptedString{
+		Version:      encryptedStringVersionNilSalt,
+		KeyID:        keyID,
+		KeyNamespace: options.keyNamespace,
+		Algorithm:    AlgorithmRSAOAEPSHA256,
+		Compression:  string(options.compression),
+		Purpose:      options.purpose,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	data, err = compress(options.compression, data)
+	if err != nil {
+		return nil, err
+	}
+
+	es.Data, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, data, es.rsaLabel(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return &es, nil
+}
+
+// rsaLabel returns the OAEP label, which binds the ciphertext to the object
+// ID, purpose and additional data the same way the derived key and
+// additional data do for the symmetric algorithms.
+func (es *EncryptedString) rsaLabel(id string) []byte {
+	label := []byte(id + "\x00" + es.Purpose + "\x00")
+
+	return append(label, es.additionalData()...)
+}
+
+func (es *EncryptedString) decryptRSA(id, privateKeyPEM string) ([]byte, error) {
+	privateKey, err := parseRSAPrivateKeyPEM([]byte(privateKeyPEM))
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := rsa.DecryptOAEP(sha256.New(), nil, privateKey, es.Data, es.rsaLabel(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+
+	return decompress(CompressionAlgo(es.Compression), decrypted)
+}
+
+// parseRSAPublicKeyPEM parses a PKIX ("PUBLIC KEY") or PKCS #1 ("RSA PUBLIC
+// KEY") encoded RSA public key.
+func parseRSAPublicKeyPEM(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("crypto: RSA public key is not PEM encoded")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("crypto: public key is not an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+// parseRSAPrivateKeyPEM parses a PKCS #8 ("PRIVATE KEY") or PKCS #1 ("RSA
+// PRIVATE KEY") encoded RSA private key.
+func parseRSAPrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("crypto: RSA private key is not PEM encoded")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("crypto: private key is not an RSA key")
+	}
+
+	return privateKey, nil
+}
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func TestEncryptedStringRSA(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicPKIX := encodePEM("PUBLIC KEY", must(x509.MarshalPKIXPublicKey(&privateKey.PublicKey)))
+	publicPKCS1 := encodePEM("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&privateKey.PublicKey))
+	privatePKCS8 := string(encodePEM("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(privateKey))))
+	privatePKCS1 := string(encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey)))
+
+	for _, publicKey := range [][]byte{publicPKIX, publicPKCS1} {
+		es, err := NewEncryptedStringRSA(id, []byte("data"), "rsa-key", publicKey, WithKeyNamespace("payments"), WithPurpose("export"), WithCompression(CompressionGzip))
+		require.NoError(t, err)
+		assert.Equal(t, AlgorithmRSAOAEPSHA256, es.Algorithm)
+		assert.Empty(t, es.Nonce)
+
+		dec, err := ParseEncryptedStringE(es.String())
+		require.NoError(t, err)
+
+		for _, privateKey := range []string{privatePKCS8, privatePKCS1} {
+			decrypted, err := dec.Decrypt(id, map[string]string{
+				"payments/rsa-key": privateKey,
+			}, WithKeyNamespace("payments"), WithPurpose("export"), WithFIPSMode())
+			require.NoError(t, err)
+			assert.Equal(t, []byte("data"), decrypted)
+		}
+
+		// bound to the object ID
+		_, err = dec.Decrypt(uuid.Must(uuid.NewV4()).String(), map[string]string{
+			"payments/rsa-key": privatePKCS8,
+		}, WithKeyNamespace("payments"), WithPurpose("export"))
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+		// bound to the purpose
+		dec.Purpose = "other"
+		_, err = dec.Decrypt(id, map[string]string{
+			"payments/rsa-key": privatePKCS8,
+		}, WithKeyNamespace("payments"), WithPurpose("other"))
+		assert.ErrorIs(t, err, ErrDecryptionFailed)
+	}
+}
+
+func TestEncryptedStringRSANegative(t *testing.T) {
+	id := uuid.Must(uuid.NewV4()).String()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	publicKey := encodePEM("PUBLIC KEY", must(x509.MarshalPKIXPublicKey(&privateKey.PublicKey)))
+
+	publicExamples := [][]byte{
+		[]byte("not-pem"),
+		encodePEM("PUBLIC KEY", []byte("not-der")),
+		encodePEM("PUBLIC KEY", must(x509.MarshalPKIXPublicKey(edPublicKey))),
+		encodePEM("PUBLIC KEY", must(x509.MarshalPKIXPublicKey(&smallKey.PublicKey))),
+	}
+
+	for _, example := range publicExamples {
+		_, err := NewEncryptedStringRSA(id, []byte("data"), "rsa-key", example)
+		assert.Error(t, err)
+	}
+
+	// too long for RSA-OAEP with a 2048 bit key
+	_, err = NewEncryptedStringRSA(id, []byte(strings.Repeat("a", 256)), "rsa-key", publicKey)
+	assert.Error(t, err)
+
+	// invalid compression
+	_, err = NewEncryptedStringRSA(id, []byte("data"), "rsa-key", publicKey, WithCompression("invalid"))
+	assert.Error(t, err)
+
+	es, err := NewEncryptedStringRSA(id, []byte("data"), "rsa-key", publicKey)
+	require.NoError(t, err)
+
+	privateExamples := []string{
+		"not-pem",
+		string(encodePEM("PRIVATE KEY", []byte("not-der"))),
+		string(encodePEM("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(edPrivateKey)))),
+		string(encodePEM("PRIVATE KEY", must(x509.MarshalPKCS8PrivateKey(smallKey)))),
+	}
+
+	for _, example := range privateExamples {
+		_, err := es.Decrypt(id, map[string]string{
+			"rsa-key": example,
+		})
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// stellarAccountIDVersion is the StrKey version byte of account IDs, which
+// makes them start with "G".
+const stellarAccountIDVersion = 6 << 3
+
+// stellarCRC16 computes the CRC16-XModem checksum used by StrKey.
+func stellarCRC16(data []byte) uint16 {
+	var crc uint16
+
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+
+		for i := 0; i < 8; i += 1 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// decodeStellarAddress returns the Ed25519 public key of a StrKey encoded
+// Stellar account ID: base32 of a version byte, the 32 byte key and a
+// little-endian CRC16-XModem checksum of both.
+func decodeStellarAddress(stellarAddress string) (ed25519.PublicKey, error) {
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(stellarAddress)
+	if err != nil || len(decoded) != 1+ed25519.PublicKeySize+2 || decoded[0] != stellarAccountIDVersion {
+		return nil, errors.New("crypto: Stellar address is not a valid account ID")
+	}
+
+	payload := decoded[:len(decoded)-2]
+
+	if binary.LittleEndian.Uint16(decoded[len(decoded)-2:]) != stellarCRC16(payload) {
+		return nil, errors.New("crypto: Stellar address checksum is not valid")
+	}
+
+	return ed25519.PublicKey(payload[1:]), nil
+}
+
+// VerifyStellarSignature checks a base64 encoded Ed25519 signature over the
+// raw message bytes against a Stellar account ID (G... address).
+func VerifyStellarSignature(message string, signature string, stellarAddress string) error {
+	publicKey, err := decodeStellarAddress(stellarAddress)
+	if err != nil {
+		return err
+	}
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(signatureBytes) != ed25519.SignatureSize {
+		return errors.New("crypto: Stellar signature must be 64 bytes encoded as base64")
+	}
+
+	if !ed25519.Verify(publicKey, []byte(message), signatureBytes) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
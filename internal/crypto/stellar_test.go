@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stellarAddress(publicKey ed25519.PublicKey) string {
+	payload := append([]byte{stellarAccountIDVersion}, publicKey...)
+	payload = binary.LittleEndian.AppendUint16(payload, stellarCRC16(payload))
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(payload)
+}
+
+func TestDecodeStellarAddress(t *testing.T) {
+	// account IDs from the Stellar documentation
+	for _, address := range []string{
+		"GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7",
+		"GCEZWKCA5VLDNRLN3RPRJMRZOX3Z6G5CHCGSNFHEYVXM3XOJMDS674JZ",
+	} {
+		publicKey, err := decodeStellarAddress(address)
+		require.NoError(t, err, address)
+		require.Equal(t, address, stellarAddress(publicKey))
+	}
+}
+
+func TestVerifyStellarSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	address := stellarAddress(publicKey)
+	require.Len(t, address, 56)
+	require.Equal(t, byte('G'), address[0])
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("hello")))
+
+	require.NoError(t, VerifyStellarSignature("hello", signature, address))
+	require.ErrorIs(t, VerifyStellarSignature("goodbye", signature, address), ErrSignatureMismatch)
+
+	// bad checksum
+	payload, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(address)
+	require.NoError(t, err)
+
+	payload[len(payload)-1] ^= 1
+	require.EqualError(t, VerifyStellarSignature("hello", signature, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(payload)), "crypto: Stellar address checksum is not valid")
+
+	// secret seed instead of an account ID
+	seed := append([]byte{18 << 3}, privateKey.Seed()...)
+	seed = binary.LittleEndian.AppendUint16(seed, stellarCRC16(seed))
+	require.EqualError(t, VerifyStellarSignature("hello", signature, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(seed)), "crypto: Stellar address is not a valid account ID")
+
+	// not base32
+	require.EqualError(t, VerifyStellarSignature("hello", signature, "!!!"), "crypto: Stellar address is not a valid account ID")
+
+	// not base64
+	require.EqualError(t, VerifyStellarSignature("hello", "!!!", address), "crypto: Stellar signature must be 64 bytes encoded as base64")
+
+	// short signature
+	require.EqualError(t, VerifyStellarSignature("hello", "AQAB", address), "crypto: Stellar signature must be 64 bytes encoded as base64")
+}
@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// totpPeriod is the time step of TOTP codes, as used by authenticator apps.
+const totpPeriod = 30
+
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret generates a random 160 bit TOTP secret encoded in
+// unpadded base32, which is the format authenticator apps expect.
+func GenerateTOTPSecret() string {
+	secret := make([]byte, 20)
+	must(io.ReadFull(rand.Reader, secret))
+
+	return totpSecretEncoding.EncodeToString(secret)
+}
+
+// totpOptions returns the options for codes with the given number of
+// digits, matching the HMAC-SHA1 and 30 second steps used to verify MFA
+// factors.
+func totpOptions(digits int, skew uint) totp.ValidateOpts {
+	return totp.ValidateOpts{
+		Period:    totpPeriod,
+		Skew:      skew,
+		Digits:    otp.Digits(digits),
+		Algorithm: otp.AlgorithmSHA1,
+	}
+}
+
+// GenerateTOTP generates the RFC 6238 code for the base32 encoded secret at
+// time t, using HMAC-SHA1 and 30 second steps. The secret may be lower case
+// and padded. The code can be hashed with GenerateTokenHash like any other
+// OTP.
+func GenerateTOTP(secret string, t time.Time, digits int) (string, error) {
+	if digits < 6 || digits > 8 {
+		return "", errors.New("crypto: TOTP must have between 6 and 8 digits")
+	}
+
+	code, err := totp.GenerateCodeCustom(strings.TrimRight(secret, "="), t, totpOptions(digits, 0))
+	if err != nil {
+		return "", fmt.Errorf("crypto: TOTP secret is not valid base32: %w", err)
+	}
+
+	return code, nil
+}
+
+// VerifyTOTP checks the code against the secret at the current time,
+// accepting codes up to window steps before or after it to tolerate clock
+// skew.
+func VerifyTOTP(secret, code string, window int) bool {
+	return verifyTOTPAt(secret, code, window, time.Now())
+}
+
+func verifyTOTPAt(secret, code string, window int, t time.Time) bool {
+	if window < 0 || len(code) < 6 || len(code) > 8 {
+		return false
+	}
+
+	valid, err := totp.ValidateCustom(code, strings.TrimRight(secret, "="), t.UTC(), totpOptions(len(code), uint(window)))
+
+	return err == nil && valid
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base32 encoding of the "12345678901234567890" secret from RFC 6238
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPRFC6238(t *testing.T) {
+	examples := []struct {
+		time int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, example := range examples {
+		code, err := GenerateTOTP(rfc6238Secret, time.Unix(example.time, 0), 8)
+		require.NoError(t, err)
+		assert.Equal(t, example.code, code)
+	}
+
+	// lowercase and padded secrets are accepted
+	code, err := GenerateTOTP("gezdgnbvgy3tqojqgezdgnbvgy3tqojq====", time.Unix(59, 0), 6)
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestGenerateTOTPNegative(t *testing.T) {
+	_, err := GenerateTOTP(rfc6238Secret, time.Now(), 5)
+	assert.Error(t, err)
+
+	_, err = GenerateTOTP(rfc6238Secret, time.Now(), 9)
+	assert.Error(t, err)
+
+	_, err = GenerateTOTP("not base32!", time.Now(), 6)
+	assert.Error(t, err)
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret := GenerateTOTPSecret()
+	assert.Len(t, secret, 32)
+	assert.NotEqual(t, secret, GenerateTOTPSecret())
+
+	code, err := GenerateTOTP(secret, time.Now(), 6)
+	require.NoError(t, err)
+	assert.True(t, VerifyTOTP(secret, code, 1))
+
+	now := time.Unix(1111111111, 0)
+
+	previous, err := GenerateTOTP(rfc6238Secret, now.Add(-30*time.Second), 6)
+	require.NoError(t, err)
+
+	assert.False(t, verifyTOTPAt(rfc6238Secret, previous, 0, now))
+	assert.True(t, verifyTOTPAt(rfc6238Secret, previous, 1, now))
+	assert.False(t, verifyTOTPAt(rfc6238Secret, "000000", 1, now))
+	assert.False(t, verifyTOTPAt(rfc6238Secret, "12345", 1, now))
+	assert.False(t, verifyTOTPAt("not base32!", previous, 1, now))
+	assert.False(t, verifyTOTPAt(rfc6238Secret, previous, -1, now))
+}
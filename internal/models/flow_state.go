@@ -1,15 +1,14 @@
 package models
 
 import (
-	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql"
-	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/supabase/auth/internal/crypto"
 	"github.com/supabase/auth/internal/storage"
 
 	"github.com/gofrs/uuid"
@@ -136,9 +135,7 @@ func FindFlowStateByUserID(tx *storage.Connection, id string, authenticationMeth
 func (f *FlowState) VerifyPKCE(codeVerifier string) error {
 	switch f.CodeChallengeMethod {
 	case SHA256.String():
-		hashedCodeVerifier := sha256.Sum256([]byte(codeVerifier))
-		encodedCodeVerifier := base64.RawURLEncoding.EncodeToString(hashedCodeVerifier[:])
-		if subtle.ConstantTimeCompare([]byte(f.CodeChallenge), []byte(encodedCodeVerifier)) != 1 {
+		if !crypto.VerifyPKCEChallenge(codeVerifier, f.CodeChallenge) {
 			return errors.New(InvalidCodeChallengeError)
 		}
 	case Plain.String():
@@ -389,6 +389,10 @@ func (ts *UserTestSuite) TestNewUserWithPasswordHashSuccess() {
 			desc: "Valid Firebase scrypt hash",
 			hash: "$fbscrypt$v=1,n=14,r=8,p=1,ss=Bw==,sk=ou9tdYTGyYm8kuR6Dt0Bp0kDuAYoXrK16mbZO4yGwAn3oLspjnN0/c41v8xZnO1n14J3MjKj1b2g6AUCAlFwMw==$C0sHCg9ek77hsg==$ZGlmZmVyZW50aGFzaA==",
 		},
+		{
+			desc: "Valid PBKDF2-SHA256 hash",
+			hash: "$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		},
 	}
 
 	for _, c := range cases {
@@ -417,6 +421,14 @@ func (ts *UserTestSuite) TestNewUserWithPasswordHashFailure() {
 			desc: "Invalid scrypt hash",
 			hash: "$fbscrypt$invalid",
 		},
+		{
+			desc: "Invalid PBKDF2-SHA256 hash",
+			hash: "$pbkdf2-sha256$invalid",
+		},
+		{
+			desc: "PBKDF2-SHA256 hash with too many iterations",
+			hash: "$pbkdf2-sha256$i=4000000000$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE",
+		},
 	}
 
 	for _, c := range cases {
@@ -465,3 +477,23 @@ func (ts *UserTestSuite) TestAuthenticate() {
 		})
 	}
 }
+
+func (ts *UserTestSuite) TestAuthenticatePBKDF2() {
+	// uses the `test` password
+	hash := "$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$4+KBqsOVLqj6E9Td14CGM3lT9IqBoXdgdB7aKUjWvVE"
+
+	u, err := NewUserWithPasswordHash("", "", hash, "", nil)
+	require.NoError(ts.T(), err)
+	require.NoError(ts.T(), ts.db.Create(u))
+
+	isAuthenticated, _, err := u.Authenticate(context.Background(), ts.db, "test", nil, false, "")
+	require.NoError(ts.T(), err)
+	require.True(ts.T(), isAuthenticated)
+
+	isAuthenticated, _, err = u.Authenticate(context.Background(), ts.db, "test1", nil, false, "")
+	require.NoError(ts.T(), err)
+	require.False(ts.T(), isAuthenticated)
+
+	// imported hashes are kept as they are
+	require.Equal(ts.T(), hash, *u.EncryptedPassword)
+}
@@ -1,7 +1,11 @@
 package siws
 
 import (
+	"errors"
+	"net/url"
 	"regexp"
+	"slices"
+	"strings"
 )
 
 var domainPattern = regexp.MustCompile(`^(localhost|(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,})(?::\d{1,5})?$`)
@@ -10,8 +14,52 @@ func IsValidDomain(domain string) bool {
 	return domainPattern.MatchString(domain)
 }
 
+// ErrInvalidChainID is returned when a message uses a chain ID that is not
+// in the "solana:<network>" format.
+var ErrInvalidChainID = errors.New("siws: Chain ID is not valid")
+
+var solanaChainIDPattern = regexp.MustCompile("^solana:[a-zA-Z0-9-]{1,32}$")
+
 var validSolanaNetworksPattern = regexp.MustCompile("^solana:(main|dev|test|local)net$")
 
+// IsValidSolanaChainID checks that the chain ID has the "solana:<network>"
+// format, regardless of whether the network is accepted.
+func IsValidSolanaChainID(chainID string) bool {
+	return solanaChainIDPattern.MatchString(chainID)
+}
+
 func IsValidSolanaNetwork(network string) bool {
 	return validSolanaNetworksPattern.MatchString(network)
 }
+
+// IsAllowedSolanaNetwork checks that the network is mainnet, devnet,
+// testnet or localnet, or one of the additional networks, such as
+// "solana:my-cluster" for a local validator cluster.
+func IsAllowedSolanaNetwork(network string, networks []string) bool {
+	return IsValidSolanaNetwork(network) || slices.Contains(networks, network)
+}
+
+// ValidateOrigin checks that a browser Origin header belongs to the domain
+// the message was signed for. The host and port of the origin must equal
+// the domain, ignoring case, and the origin must use HTTPS unless it's
+// localhost.
+func ValidateOrigin(origin, domain string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || u.Path != "" {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if u.Hostname() != "localhost" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	// browsers send lower case origins, while the domain in the
+	// message may be mixed case
+	return strings.EqualFold(u.Host, domain)
+}
@@ -1 +1,51 @@
 package siws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOrigin(t *testing.T) {
+	examples := []struct {
+		origin string
+		domain string
+		valid  bool
+	}{
+		{"https://supabase.com", "supabase.com", true},
+		{"https://supabase.com:8443", "supabase.com:8443", true},
+		{"http://localhost:3000", "localhost:3000", true},
+		{"https://example.com", "Example.com", true},
+		{"https://example.com", "EXAMPLE.COM", true},
+		{"https://supabase.com", "supabase.green", false},
+		{"https://supabase.com:8443", "supabase.com", false},
+		{"https://supabase.com", "supabase.com:443", false},
+		{"http://supabase.com", "supabase.com", false},
+		{"ftp://supabase.com", "supabase.com", false},
+		{"https://supabase.com/path", "supabase.com", false},
+		{"null", "supabase.com", false},
+		{"", "supabase.com", false},
+		{"https://%zz", "supabase.com", false},
+	}
+
+	for _, example := range examples {
+		require.Equal(t, example.valid, ValidateOrigin(example.origin, example.domain), example.origin+" "+example.domain)
+	}
+}
+
+func TestIsAllowedSolanaNetwork(t *testing.T) {
+	for _, network := range []string{"solana:mainnet", "solana:devnet", "solana:testnet", "solana:localnet"} {
+		require.True(t, IsAllowedSolanaNetwork(network, nil), network)
+	}
+
+	require.False(t, IsAllowedSolanaNetwork("solana:my-cluster", nil))
+	require.True(t, IsAllowedSolanaNetwork("solana:my-cluster", []string{"solana:my-cluster"}))
+
+	// additional networks are not shared between callers
+	require.False(t, IsAllowedSolanaNetwork("solana:my-cluster", []string{"solana:other-cluster"}))
+
+	for _, chainID := range []string{"", "solana:", "my-cluster", "ethereum:1", "solana:my cluster"} {
+		require.False(t, IsValidSolanaChainID(chainID), chainID)
+		require.False(t, IsAllowedSolanaNetwork(chainID, nil), chainID)
+	}
+}
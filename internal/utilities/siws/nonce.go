@@ -0,0 +1,99 @@
+package siws
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNonceSeen is returned when a message with an already used nonce is
+// presented again.
+var ErrNonceSeen = errors.New("siws: Nonce has already been used")
+
+// ErrNonceRequired is returned by CheckReplay when the message has no
+// nonce to record.
+var ErrNonceRequired = errors.New("siws: Nonce is required to prevent replay")
+
+// NonceStore remembers the nonces of messages that were used to sign in, so
+// the same signed message can't be replayed within its validity window.
+//
+// MarkSeen must atomically record the nonce until expiry and return
+// ErrNonceSeen if the nonce is already recorded and not yet expired. A
+// Postgres store would use an insert that fails on conflict, a Redis store
+// SET with NX and an expiry. Nonces may be forgotten once they expire.
+type NonceStore interface {
+	MarkSeen(nonce string, expiry time.Time) error
+}
+
+// CheckReplay records the message's nonce in the store and fails with
+// ErrNonceSeen if it was used before. The nonce is remembered until the
+// message expires, or for maxValidity after it was issued if it has no
+// expiration time. A nil store disables the check.
+func (m *SIWSMessage) CheckReplay(store NonceStore, maxValidity time.Duration) error {
+	if store == nil {
+		return nil
+	}
+
+	if m.Nonce == "" {
+		return ErrNonceRequired
+	}
+
+	expiry := m.ExpirationTime
+	if expiry.IsZero() {
+		expiry = m.IssuedAt.Add(maxValidity)
+	}
+
+	return store.MarkSeen(m.Nonce, expiry)
+}
+
+// minNonceSweep is the number of nonces a MemoryNonceStore holds before it
+// first sweeps out expired ones.
+const minNonceSweep = 1024
+
+// MemoryNonceStore is a NonceStore that keeps nonces in memory. It is only
+// suitable for a single server process; deployments with multiple
+// instances need a shared store.
+//
+// Expired nonces are swept out once the store has doubled in size since
+// the last sweep, so MarkSeen takes amortized constant time.
+type MemoryNonceStore struct {
+	mu      sync.Mutex
+	nonces  map[string]time.Time
+	sweepAt int
+
+	now func() time.Time
+}
+
+// NewMemoryNonceStore creates an empty MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces:  make(map[string]time.Time),
+		sweepAt: minNonceSweep,
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryNonceStore) MarkSeen(nonce string, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if seenExpiry, ok := s.nonces[nonce]; ok && now.Before(seenExpiry) {
+		return ErrNonceSeen
+	}
+
+	s.nonces[nonce] = expiry
+
+	if len(s.nonces) >= s.sweepAt {
+		for seenNonce, seenExpiry := range s.nonces {
+			if !now.Before(seenExpiry) {
+				delete(s.nonces, seenNonce)
+			}
+		}
+
+		s.sweepAt = max(2*len(s.nonces), minNonceSweep)
+	}
+
+	return nil
+}
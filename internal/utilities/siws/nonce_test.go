@@ -0,0 +1,83 @@
+package siws
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingNonceStore struct{}
+
+func (failingNonceStore) MarkSeen(nonce string, expiry time.Time) error {
+	return errors.New("store unavailable")
+}
+
+func TestCheckReplay(t *testing.T) {
+	now := time.Now()
+
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time {
+		return now
+	}
+
+	m := &SIWSMessage{
+		Nonce:    "abc123",
+		IssuedAt: now,
+	}
+
+	require.NoError(t, m.CheckReplay(nil, time.Minute))
+
+	require.NoError(t, m.CheckReplay(store, time.Minute))
+	require.Equal(t, ErrNonceSeen, m.CheckReplay(store, time.Minute))
+
+	// expiration time takes precedence over the maximum validity
+	other := &SIWSMessage{
+		Nonce:          "def456",
+		IssuedAt:       now,
+		ExpirationTime: now.Add(time.Hour),
+	}
+	require.NoError(t, other.CheckReplay(store, time.Minute))
+
+	// the first nonce expired, the second one did not
+	now = now.Add(2 * time.Minute)
+
+	require.Equal(t, ErrNonceSeen, other.CheckReplay(store, time.Minute))
+	require.Equal(t, ErrNonceSeen, store.MarkSeen("def456", now.Add(time.Hour)))
+
+	require.NoError(t, m.CheckReplay(store, time.Minute))
+
+	require.Equal(t, ErrNonceRequired, (&SIWSMessage{}).CheckReplay(store, time.Minute))
+	require.EqualError(t, m.CheckReplay(failingNonceStore{}, time.Minute), "store unavailable")
+}
+
+func TestMemoryNonceStoreSweep(t *testing.T) {
+	now := time.Now()
+
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time {
+		return now
+	}
+
+	// expired nonces are kept until the store reaches the sweep size
+	for i := 0; i < minNonceSweep-1; i++ {
+		require.NoError(t, store.MarkSeen(strconv.Itoa(i), now.Add(time.Minute)))
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	require.Len(t, store.nonces, minNonceSweep-1)
+	require.NoError(t, store.MarkSeen("live", now.Add(time.Minute)))
+	require.Len(t, store.nonces, 1)
+	require.Equal(t, minNonceSweep, store.sweepAt)
+
+	// the sweep size grows with the live nonces
+	for i := 0; i < minNonceSweep; i++ {
+		require.NoError(t, store.MarkSeen("live-"+strconv.Itoa(i), now.Add(time.Minute)))
+	}
+
+	require.Len(t, store.nonces, minNonceSweep+1)
+	require.Equal(t, 2*minNonceSweep, store.sweepAt)
+}
@@ -34,6 +34,8 @@ const headerSuffix = " wants you to sign in with your Solana account:"
 
 var addressPattern = regexp.MustCompile("^[a-zA-Z0-9]{32,44}$")
 
+var noncePattern = regexp.MustCompile("^[a-zA-Z0-9]+$")
+
 func ParseMessage(raw string) (*SIWSMessage, error) {
 	lines := strings.Split(raw, "\n")
 	if len(lines) < 6 {
@@ -124,6 +126,10 @@ func ParseMessage(raw string) (*SIWSMessage, error) {
 			msg.ChainID = value
 
 		case "Nonce":
+			if !noncePattern.MatchString(value) {
+				return nil, errors.New("siws: Nonce must only contain alphanumeric characters")
+			}
+
 			msg.Nonce = value
 
 		case "Issued At":
@@ -173,8 +179,8 @@ func ParseMessage(raw string) (*SIWSMessage, error) {
 		return nil, errors.New("siws: URI is not specified")
 	}
 
-	if msg.ChainID != "" && !IsValidSolanaNetwork(msg.ChainID) {
-		return nil, errors.New("siws: Chain ID is not valid")
+	if msg.ChainID != "" && !IsValidSolanaChainID(msg.ChainID) {
+		return nil, ErrInvalidChainID
 	}
 
 	if !msg.IssuedAt.IsZero() && !msg.ExpirationTime.IsZero() {
@@ -197,3 +203,25 @@ func (m *SIWSMessage) VerifySignature(signature []byte) bool {
 
 	return ed25519.Verify(pubKey, []byte(m.Raw), signature)
 }
+
+// Clone returns a deep copy of the message, so that it can be modified
+// without affecting the original.
+func (m *SIWSMessage) Clone() *SIWSMessage {
+	clone := *m
+
+	if m.URI != nil {
+		uri := *m.URI
+		clone.URI = &uri
+	}
+
+	if m.Resources != nil {
+		clone.Resources = make([]*url.URL, len(m.Resources))
+
+		for i, resource := range m.Resources {
+			resourceURL := *resource
+			clone.Resources[i] = &resourceURL
+		}
+	}
+
+	return &clone
+}
@@ -2,7 +2,9 @@ package siws
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -84,6 +86,14 @@ func TestParseMessage(t *testing.T) {
 			example: "domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z\nChain ID: random:mainnet",
 			error:   "Chain ID is not valid",
 		},
+		{
+			example: "domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z\nNonce: abc+def/123=",
+			error:   "Nonce must only contain alphanumeric characters",
+		},
+		{
+			example: "domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z\nNonce:",
+			error:   "Nonce must only contain alphanumeric characters",
+		},
 	}
 
 	for i, example := range negativeExamples {
@@ -124,3 +134,119 @@ func TestParseMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestSIWSMessageClone(t *testing.T) {
+	original, err := ParseMessage("domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nStatement\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z\nExpiration Time: 2025-01-02T00:00:00Z\nResources:\n- https://google.com\n")
+	require.NoError(t, err)
+
+	clone := original.Clone()
+	require.Equal(t, original, clone)
+
+	clone.Domain = "other.com"
+	clone.URI.Host = "other.com"
+	clone.Resources[0].Host = "other.com"
+	clone.Resources = append(clone.Resources, clone.URI)
+	clone.ExpirationTime = clone.ExpirationTime.Add(time.Hour)
+
+	require.Equal(t, "domain.com", original.Domain)
+	require.Equal(t, "https://domain.com", original.URI.String())
+	require.Len(t, original.Resources, 1)
+	require.Equal(t, "https://google.com", original.Resources[0].String())
+	require.Equal(t, "2025-01-02 00:00:00 +0000 UTC", original.ExpirationTime.String())
+}
+
+// constructMessage serializes a parsed message back into the SIWS format.
+func constructMessage(m *SIWSMessage) string {
+	lines := []string{
+		m.Domain + headerSuffix,
+		m.Address,
+		"",
+	}
+
+	if m.Statement != "" {
+		lines = append(lines, m.Statement, "")
+	}
+
+	lines = append(lines,
+		"URI: "+m.URI.String(),
+		"Version: "+m.Version,
+		"Issued At: "+m.IssuedAt.Format(time.RFC3339Nano),
+	)
+
+	if m.ChainID != "" {
+		lines = append(lines, "Chain ID: "+m.ChainID)
+	}
+
+	if m.Nonce != "" {
+		lines = append(lines, "Nonce: "+m.Nonce)
+	}
+
+	if !m.NotBefore.IsZero() {
+		lines = append(lines, "Not Before: "+m.NotBefore.Format(time.RFC3339Nano))
+	}
+
+	if !m.ExpirationTime.IsZero() {
+		lines = append(lines, "Expiration Time: "+m.ExpirationTime.Format(time.RFC3339Nano))
+	}
+
+	if m.RequestID != "" {
+		lines = append(lines, "Request ID: "+m.RequestID)
+	}
+
+	if len(m.Resources) > 0 {
+		lines = append(lines, "Resources:")
+
+		for _, resource := range m.Resources {
+			lines = append(lines, "- "+resource.String())
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func FuzzSIWSRoundtrip(f *testing.F) {
+	f.Add("domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nStatement\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z\nNonce: 123\nRequest ID: abcdef\nChain ID: solana:testnet")
+	f.Add("domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nVersion: 1\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00.123+02:00\nNot Before: 2025-01-01T00:00:00Z\nExpiration Time: 2025-01-02T00:00:00Z")
+	f.Add("localhost:3000 wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nStatement\n\nVersion: 1\nURI: http://localhost:3000/login\nIssued At: 2025-01-01T00:00:00Z\nResources:\n- https://google.com\n- https://example.com/path?q=1\n")
+	f.Add("domain.com wants you to sign in with your Solana account:\n4Cw1koUQtqybLFem7uqhzMBznMPGARbFS4cjaYbM9RnR\n\nStatement\n\nVersion: 2\nURI: https://domain.com\nIssued At: 2025-01-01T00:00:00Z")
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		parsed, err := ParseMessage(raw)
+		if err != nil {
+			require.Nil(t, parsed)
+			return
+		}
+
+		require.NotNil(t, parsed)
+		require.Equal(t, raw, parsed.Raw)
+		require.NotEmpty(t, parsed.Domain)
+		require.NotEmpty(t, parsed.Address)
+		require.NotNil(t, parsed.URI)
+		require.Equal(t, "1", parsed.Version)
+		require.False(t, parsed.IssuedAt.IsZero())
+
+		constructed := constructMessage(parsed)
+
+		reparsed, err := ParseMessage(constructed)
+		require.NoError(t, err, constructed)
+
+		require.Equal(t, parsed.Domain, reparsed.Domain)
+		require.Equal(t, parsed.Address, reparsed.Address)
+		require.Equal(t, parsed.Statement, reparsed.Statement)
+		require.Equal(t, parsed.URI.String(), reparsed.URI.String())
+		require.Equal(t, parsed.Version, reparsed.Version)
+		require.Equal(t, parsed.Nonce, reparsed.Nonce)
+		require.True(t, parsed.IssuedAt.Equal(reparsed.IssuedAt))
+		require.Equal(t, parsed.ChainID, reparsed.ChainID)
+		require.True(t, parsed.NotBefore.Equal(reparsed.NotBefore))
+		require.Equal(t, parsed.RequestID, reparsed.RequestID)
+		require.True(t, parsed.ExpirationTime.Equal(reparsed.ExpirationTime))
+		require.Len(t, reparsed.Resources, len(parsed.Resources))
+
+		for i, resource := range parsed.Resources {
+			require.Equal(t, resource.String(), reparsed.Resources[i].String())
+		}
+
+		require.Equal(t, constructed, constructMessage(reparsed))
+	})
+}